@@ -0,0 +1,240 @@
+package phpstart
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// AppServer is a long-running PHP application server that replaces both the
+// web server and php-fpm, such as RoadRunner, FrankenPHP or Swoole through
+// Laravel Octane. An app server is selected with $BP_PHP_SERVER, or detected
+// from the packages required in the app's composer.json.
+type AppServer struct {
+	name             string
+	composerPackages []string
+	requiredPackages []string
+	requirements     []packit.BuildPlanRequirement
+	proc             func(workingDir string) (Proc, error)
+}
+
+// NewAppServer returns an app server with the given name that is detected
+// when the app requires every one of the required packages and any of the
+// given composer packages. The proc function returns the process that starts
+// the app server, along with its reload command and health check, for an app
+// in the given directory.
+func NewAppServer(name string, requiredPackages, composerPackages []string, requirements []packit.BuildPlanRequirement, proc func(workingDir string) (Proc, error)) AppServer {
+	return AppServer{
+		name:             name,
+		composerPackages: composerPackages,
+		requiredPackages: requiredPackages,
+		requirements:     requirements,
+		proc:             proc,
+	}
+}
+
+// NewSwooleServer returns an app server that runs Laravel Octane with Swoole
+// or Open Swoole. Since Octane also runs on RoadRunner and FrankenPHP, it is
+// only detected when the app requires the swoole or openswoole extension, or
+// its IDE helper, next to laravel/octane.
+func NewSwooleServer() AppServer {
+	return NewAppServer(Swoole, []string{"laravel/octane"}, []string{"ext-swoole", "ext-openswoole", "swoole/ide-helper", "openswoole/ide-helper", "openswoole/core"}, phpLaunchRequirements(), func(workingDir string) (Proc, error) {
+		return octaneProc(workingDir, "swoole")
+	})
+}
+
+// NewRoadRunnerServer returns an app server that runs RoadRunner with the
+// app's .rr.yaml, or through Laravel Octane when the app requires
+// laravel/octane. Both run the rr binary, which is required at launch.
+func NewRoadRunnerServer() AppServer {
+	requirements := append(phpLaunchRequirements(), packit.BuildPlanRequirement{
+		Name: Rr,
+		Metadata: BuildPlanMetadata{
+			Launch: true,
+		},
+	})
+
+	return NewAppServer(RoadRunner, nil, []string{"spiral/roadrunner", "spiral/roadrunner-http", "spiral/roadrunner-cli"}, requirements, func(workingDir string) (Proc, error) {
+		requires, err := composerRequires(workingDir)
+		if err != nil {
+			return Proc{}, err
+		}
+
+		if _, ok := requires["laravel/octane"]; ok {
+			return octaneProc(workingDir, "roadrunner")
+		}
+
+		config := filepath.Join(workingDir, ".rr.yaml")
+		proc := NewProc("rr", []string{"serve", "-c", config, "-w", workingDir})
+		proc.ReloadCommand = []string{"rr", "reset", "-c", config, "-w", workingDir}
+		proc.HealthCheck, err = roadRunnerHealthCheck(config)
+		if err != nil {
+			return Proc{}, err
+		}
+		return proc, nil
+	})
+}
+
+// roadRunnerHealthCheck returns a probe of the HTTP plugin through the status
+// plugin when the RoadRunner configuration at configPath enables it, or a
+// check that connects to the address of the HTTP plugin otherwise. It is nil
+// when the configuration sets neither address.
+func roadRunnerHealthCheck(configPath string) (*HealthCheck, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RoadRunner configuration: %w", err)
+	}
+
+	var config struct {
+		HTTP struct {
+			Address string `yaml:"address"`
+		} `yaml:"http"`
+		Status struct {
+			Address string `yaml:"address"`
+		} `yaml:"status"`
+	}
+	err = yaml.Unmarshal(content, &config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RoadRunner configuration: %w", err)
+	}
+
+	switch {
+	case config.Status.Address != "":
+		return &HealthCheck{URL: fmt.Sprintf("http://%s/health?plugin=http", localAddress(config.Status.Address))}, nil
+	case config.HTTP.Address != "":
+		return &HealthCheck{Socket: localAddress(config.HTTP.Address)}, nil
+	}
+
+	return nil, nil
+}
+
+// localAddress returns the loopback address for an address that listens on
+// all interfaces, such as 0.0.0.0:8080 or :8080, so that it can be checked.
+func localAddress(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	switch host {
+	case "", "0.0.0.0", "::":
+		host = "127.0.0.1"
+	}
+
+	return net.JoinHostPort(host, port)
+}
+
+// NewFrankenPHPServer returns an app server that runs FrankenPHP with the
+// app's Caddyfile.
+func NewFrankenPHPServer() AppServer {
+	requirements := []packit.BuildPlanRequirement{
+		{
+			Name: FrankenPHP,
+			Metadata: BuildPlanMetadata{
+				Launch: true,
+			},
+		},
+	}
+
+	return NewAppServer(FrankenPHP, nil, []string{"runtime/frankenphp-symfony"}, requirements, func(workingDir string) (Proc, error) {
+		config := filepath.Join(workingDir, "Caddyfile")
+		proc := NewProc("frankenphp", []string{"run", "--config", config})
+		proc.ReloadCommand = []string{"frankenphp", "reload", "--config", config}
+		proc.HealthCheck = &HealthCheck{URL: "http://127.0.0.1:2019/config/"}
+		return proc, nil
+	})
+}
+
+// octaneProc returns the process that runs the app with Laravel Octane on the
+// given Octane server. Its health check probes $BP_PHP_HEALTHCHECK_PATH when
+// it is set, and otherwise connects to $PORT.
+func octaneProc(workingDir, octaneServer string) (Proc, error) {
+	artisan := filepath.Join(workingDir, "artisan")
+	proc := NewProc("php", []string{artisan, "octane:start", "--server=" + octaneServer, "--host=0.0.0.0", "--port=${PORT}"})
+	proc.ReloadCommand = []string{"php", artisan, "octane:reload", "--server=" + octaneServer}
+
+	healthCheck, err := serverHealthCheck()
+	if err != nil {
+		return Proc{}, err
+	}
+
+	if healthCheck == nil {
+		healthCheck = &HealthCheck{Socket: "127.0.0.1:${PORT}"}
+	}
+	proc.HealthCheck = healthCheck
+
+	return proc, nil
+}
+
+func (s AppServer) Name() string {
+	return s.name
+}
+
+func (s AppServer) DisplayName() string {
+	return strings.ToUpper(s.name)
+}
+
+// DetectComposer reports whether the app requires every required package of
+// the app server and any of its composer packages.
+func (s AppServer) DetectComposer(requires map[string]string) bool {
+	for _, pkg := range s.requiredPackages {
+		if _, ok := requires[pkg]; !ok {
+			return false
+		}
+	}
+
+	for _, pkg := range s.composerPackages {
+		if _, ok := requires[pkg]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s AppServer) Requirements() []packit.BuildPlanRequirement {
+	return s.requirements
+}
+
+func (AppServer) ConfigEnvVar() string {
+	return ""
+}
+
+func (AppServer) UsesFpm() bool {
+	return false
+}
+
+func (s AppServer) StartCommand(workingDir, _ string) (Proc, error) {
+	return s.proc(workingDir)
+}
+
+// ReloadSignal is empty, since app servers are reloaded through the reload
+// command of their process instead.
+func (AppServer) ReloadSignal() string {
+	return ""
+}
+
+func (AppServer) ReloadDir() string {
+	return ""
+}
+
+func (AppServer) StopSignal() string {
+	return "SIGTERM"
+}
+
+func phpLaunchRequirements() []packit.BuildPlanRequirement {
+	return []packit.BuildPlanRequirement{
+		{
+			Name: Php,
+			Metadata: BuildPlanMetadata{
+				Build:  true,
+				Launch: true,
+			},
+		},
+	}
+}
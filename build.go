@@ -1,23 +1,35 @@
 package phpstart
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/paketo-buildpacks/libreload-packit"
 	"github.com/paketo-buildpacks/packit/v2"
 	"github.com/paketo-buildpacks/packit/v2/fs"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
+	"gopkg.in/yaml.v2"
 )
 
 //go:generate faux --interface ProcMgr --output fakes/procmgr.go
 
+// ProcessesLabel is the image label that holds the resolved process table as
+// JSON, see ProcessTable.
+const ProcessesLabel = "io.paketo.php-start.processes"
+
 // ProcMgr manages all processes that the build  phase may need to run by
 // adding them to a procs.yml file for execution at launch time.
 type ProcMgr interface {
 	Add(name string, proc Proc)
+	AddSchedule(name string, schedule Schedule)
 	WriteFile(path string) error
 }
 
@@ -28,7 +40,42 @@ type ProcMgr interface {
 // of processes to run, since there are multiple process that could be run. The
 // layer is available at and launch-time, and its contents are used in the
 // image launch process.
-func Build(procs ProcMgr, logger scribe.Emitter, reloader Reloader) packit.BuildFunc {
+//
+// The layer is reused when the resolved processes, their launch environment,
+// the procmgr-binary and the live reload setting are the same as in the
+// previous build.
+//
+// The resolved process table is published in the layer metadata and in the
+// ProcessesLabel image label, and the procmgr-binary is described in the layer
+// SBOM.
+//
+// Schedules that the app declares in .php-cron.yml, or the file that
+// $BP_PHP_CRON_FILE points to, are added to the process file, see Schedule.
+// The one-shot command in $BP_PHP_INIT_COMMAND is added as an init task, see
+// Proc.Init.
+//
+// When $BP_PHP_BIND_PORT is true, the port-binder exec.d binary is added to
+// the layer for servers that implement PortBinder, so that they listen on the
+// $PORT assigned at launch.
+// When $BP_PHP_FPM_AUTOSIZE is true, the fpm-sizer exec.d binary is added to
+// size the FPM pools for the memory and CPUs of the container at launch, and
+// when $BP_PHP_SERVER_AUTOSIZE is true, the server-tuner exec.d binary is
+// added to size the workers of servers that implement WorkerTuner.
+//
+// When live reload is enabled and the configuration directories of the
+// processes exist, the given Reloader transforms the web launch process into a
+// reload-web one that restarts them when those directories change. Servers
+// with a reload command run it instead. When $BP_PHP_SOURCE_RELOAD is true,
+// changes to the app source gracefully reload FPM, see SourceReload. Each
+// process can watch extra paths, which either restart the processes or send
+// it a signal, see ReadWatchPaths.
+//
+// The server to start is looked up in the given registry, see Server.
+//
+// When $BP_PHP_CONFIG_CHECK is true, the server and FPM configuration is
+// checked at build time with the given ConfigChecker, and the build fails if
+// it does not parse.
+func Build(procs ProcMgr, logger scribe.Emitter, reloader Reloader, servers *ServerRegistry, configChecker ConfigChecker, sbomGenerator SBOMGenerator) packit.BuildFunc {
 	return func(context packit.BuildContext) (packit.BuildResult, error) {
 		logger.Title("%s %s", context.BuildpackInfo.Name, context.BuildpackInfo.Version)
 
@@ -40,111 +87,531 @@ func Build(procs ProcMgr, logger scribe.Emitter, reloader Reloader) packit.Build
 		logger.Debug.Subprocess(layer.Path)
 		logger.Break()
 
-		layer, err = layer.Reset()
+		server, serverConfPath, err := servers.buildServer(context.WorkingDir)
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
-		layer.Launch = true
 
-		httpdConfPath := os.Getenv("PHP_HTTPD_PATH")
-		nginxConfPath := os.Getenv("PHP_NGINX_PATH")
+		shouldEnableReload, err := reloader.ShouldEnableLiveReload()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
 
-		if (httpdConfPath == "" && nginxConfPath == "") ||
-			(httpdConfPath != "" && nginxConfPath != "") {
-			return packit.BuildResult{}, errors.New("need exactly one of: $PHP_HTTPD_PATH or $PHP_NGINX_PATH")
+		shouldCheckConfig, err := parseBoolEnv("BP_PHP_CONFIG_CHECK")
+		if err != nil {
+			return packit.BuildResult{}, err
 		}
 
-		shouldEnableReload, err := reloader.ShouldEnableLiveReload()
+		shouldAutosizeFpm, err := parseBoolEnv("BP_PHP_FPM_AUTOSIZE")
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		shouldAutosizeServer, err := parseBoolEnv("BP_PHP_SERVER_AUTOSIZE")
 		if err != nil {
 			return packit.BuildResult{}, err
 		}
 
+		shouldBindPort, err := parseBoolEnv("BP_PHP_BIND_PORT")
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		sourceReload, err := ReadSourceReload()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		type configCheck struct {
+			name  string
+			check Proc
+		}
+		var configChecks []configCheck
+
+		// The resolved processes are kept alongside the process manager so
+		// that they can be compared with the ones of the previous build
+		resolved := NewProcs()
+		table := ProcessTable{
+			SchemaVersion: ProcsSchemaVersion,
+			Server:        server.Name(),
+			Processes:     map[string]ProcessTableEntry{},
+		}
+		// The reload-web launch process restarts the processes when the
+		// directories they are configured from change, and the procmgr-binary
+		// sends a process a signal when one of its watch paths changes
+		reloadSpec := libreload.ReloadableProcessSpec{}
+		type liveWatch struct {
+			process string
+			path    string
+			action  string
+		}
+		var liveWatches []liveWatch
+		watchRestart := func(name string, paths ...string) {
+			for _, path := range paths {
+				reloadSpec.WatchPaths = append(reloadSpec.WatchPaths, path)
+				liveWatches = append(liveWatches, liveWatch{name, path, "restart"})
+			}
+		}
+		signalWatches := false
+
+		add := func(name string, proc Proc, reload bool) error {
+			startupTimeout, err := ReadStartupTimeout(name)
+			if err != nil {
+				return err
+			}
+			if startupTimeout != "" && proc.HealthCheck == nil && !proc.Init {
+				return fmt.Errorf("failed to apply %s: process %s has no health check to wait for", processEnvName("BP_PHP_STARTUP_TIMEOUT_", name), name)
+			}
+			proc.StartupTimeout = startupTimeout
+			proc.LogFiles = append(proc.LogFiles, ReadLogFiles(context.WorkingDir, name)...)
+
+			if shouldEnableReload {
+				restart, watch, err := ReadWatchPaths(context.WorkingDir, name)
+				if err != nil {
+					return err
+				}
+
+				watchRestart(name, restart...)
+				proc.Watch = append(proc.Watch, watch...)
+				for _, path := range proc.Watch {
+					action := path.Signal
+					if len(path.Command) > 0 {
+						action = strings.Join(path.Command, " ")
+					}
+					liveWatches = append(liveWatches, liveWatch{name, path.Path, action})
+				}
+
+				signalWatches = signalWatches || len(proc.Watch) > 0
+				reload = reload || len(restart) > 0 || len(proc.Watch) > 0
+			}
+
+			resolved.Add(name, proc)
+			procs.Add(name, proc)
+			table.Processes[name] = ProcessTableEntry{Command: proc.Command, Args: proc.Args, Reload: reload}
+			return nil
+		}
+
+		// Servers with a fixed listen port read their configuration through
+		// $PHP_START_SERVER_CONFIG, which the port-binder exec.d binary points
+		// at a copy that listens on the $PORT assigned at launch
+		startConfPath := serverConfPath
+		launchDefaults := map[string]string{}
+		var execD []string
+		if binder, ok := server.(PortBinder); ok && shouldBindPort {
+			binding := binder.PortBinding()
+			startConfPath = "${PHP_START_SERVER_CONFIG}"
+			launchDefaults[ListenDirectiveEnv] = binding.Listen
+			if binding.Include != "" {
+				launchDefaults[IncludeDirectiveEnv] = binding.Include
+			}
+			launchDefaults["PHP_START_SERVER_CONFIG"] = serverConfPath
+			execD = append(execD, "port-binder")
+		}
+
+		requiresComposerPackages, composerReason, err := requireComposerPackages(context.WorkingDir)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		if requiresComposerPackages {
+			logger.Process("Requiring composer-packages at launch: %s", composerReason)
+		} else {
+			logger.Process("Not requiring composer-packages: %s", composerReason)
+		}
+		logger.Break()
+
 		logger.Process("Determining start commands to include in procs.yml:")
-		// HTTPD Case
-		if httpdConfPath != "" {
-			serverProc := NewProc("httpd", []string{"-f", httpdConfPath, "-k", "start", "-DFOREGROUND"})
+		serverProc, err := server.StartCommand(context.WorkingDir, startConfPath)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+		serverProc.ReloadSignal = server.ReloadSignal()
+		serverProc.StopSignal = server.StopSignal()
 
-			if exists, err := fs.Exists(filepath.Join(context.WorkingDir, ".httpd.conf.d")); err != nil {
+		// Application servers come with a health check of their own
+		if serverProc.HealthCheck == nil {
+			serverProc.HealthCheck, err = serverHealthCheck()
+			if err != nil {
 				return packit.BuildResult{}, err
-			} else if shouldEnableReload && exists {
-				// HTTPD should reload configuration when it receives SIGHUP
-				// https://httpd.apache.org/docs/2.4/stopping.html
-				serverProc = NewProc("watchexec", []string{
-					"--watch", "/workspace/.httpd.conf.d",
-					"--on-busy-update", "signal",
-					"--signal", "SIGHUP",
-					"--shell", "none",
-					"--", "httpd",
-					"-f", httpdConfPath, "-k",
-					"start",
-					"-DFOREGROUND",
+			}
+		}
+
+		// The server-tuner exec.d binary sizes the workers for the CPUs of the
+		// container at launch. The launch defaults size them for a single CPU
+		// until it runs.
+		if tuner, ok := server.(WorkerTuner); ok && shouldAutosizeServer {
+			workerArgs, err := tuner.WorkerArgs(serverConfPath)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			if len(workerArgs) == 0 {
+				logger.Debug.Subprocess("%s workers will not be tuned since the configuration sets them", strings.ToUpper(server.Name()))
+			} else {
+				serverProc.Args = append(serverProc.Args, workerArgs...)
+				launchDefaults[WorkersPerCPUEnv] = FormatWorkersPerCPU(tuner.WorkersPerCPU())
+				for name, value := range TuneWorkers(tuner.WorkersPerCPU(), 1) {
+					launchDefaults[name] = value
+				}
+				execD = append(execD, "server-tuner")
+			}
+		}
+
+		serverTitle := strings.ToUpper(server.Name())
+		if tester, ok := server.(ConfigTester); ok {
+			configChecks = append(configChecks, configCheck{serverTitle, tester.ConfigTestCommand(context.WorkingDir, serverConfPath)})
+		}
+		serverReload := false
+		if reloadDir := server.ReloadDir(); reloadDir != "" {
+			if exists, err := fs.Exists(filepath.Join(context.WorkingDir, reloadDir)); err != nil {
+				return packit.BuildResult{}, err
+			} else if shouldEnableReload && exists && len(serverProc.ReloadCommand) > 0 {
+				// Servers that cannot be reloaded with a signal run their reload
+				// command when the configuration changes
+				serverProc.Watch = append(serverProc.Watch, WatchPath{
+					Path:    filepath.Join(context.WorkingDir, reloadDir),
+					Command: serverProc.ReloadCommand,
 				})
+				serverReload = true
+			} else if shouldEnableReload && exists {
+				watchRestart(server.Name(), filepath.Join(context.WorkingDir, reloadDir))
+				serverReload = true
 			} else if shouldEnableReload && !exists {
-				logger.Debug.Subprocess("HTTPD configuration will not be reloadable since .httpd.conf.d folder not found")
+				logger.Debug.Subprocess("%s configuration will not be reloadable since %s folder not found", serverTitle, reloadDir)
 			}
+		}
 
-			procs.Add("httpd", serverProc)
-			logger.Subprocess("HTTPD: %s %v", serverProc.Command, strings.Join(serverProc.Args, " "))
+		err = add(server.Name(), serverProc, serverReload)
+		if err != nil {
+			return packit.BuildResult{}, err
 		}
+		logger.Subprocess("%s: %s %v", server.DisplayName(), serverProc.Command, strings.Join(serverProc.Args, " "))
+
+		// FPM Case
+		if server.UsesFpm() {
+			fpmConfPath, ok := os.LookupEnv("PHP_FPM_PATH")
+			if !ok || fpmConfPath == "" {
+				return packit.BuildResult{}, errors.New("failed to lookup $PHP_FPM_PATH")
+			}
+
+			phprcPath, ok := os.LookupEnv("PHPRC")
+			if !ok || phprcPath == "" {
+				return packit.BuildResult{}, errors.New("failed to lookup $PHPRC path for FPM")
+			}
+			configChecks = append(configChecks, configCheck{"FPM", NewProc("php-fpm", []string{"-t", "-y", fpmConfPath, "-c", phprcPath})})
 
-		// Nginx Case
-		if nginxConfPath != "" {
-			serverProc := NewProc("nginx", []string{"-p", context.WorkingDir, "-c", nginxConfPath})
+			// The fpm-sizer exec.d binary points $PHP_START_FPM_CONFIG at a
+			// configuration that sizes the pools for the container at launch
+			if shouldAutosizeFpm {
+				launchDefaults["PHP_START_FPM_CONFIG"] = fpmConfPath
+				execD = append(execD, "fpm-sizer")
+				fpmConfPath = "${PHP_START_FPM_CONFIG}"
+			}
+			fpmProc := NewProc("php-fpm", []string{"-y", fpmConfPath, "-c", phprcPath})
 
-			if exists, err := fs.Exists(filepath.Join(context.WorkingDir, ".nginx.conf.d")); err != nil {
+			exists, err := fs.Exists(filepath.Join(context.WorkingDir, ".php.fpm.d"))
+			if err != nil {
 				return packit.BuildResult{}, err
-			} else if shouldEnableReload && exists {
-				// NGINX should reload configuration when it receives SIGHUP
-				// http://nginx.org/en/docs/control.html
-				serverProc = NewProc("watchexec", []string{
-					"--watch", "/workspace/.nginx.conf.d",
-					"--on-busy-update", "signal",
-					"--signal", "SIGHUP",
-					"--shell", "none",
-					"--", "nginx",
-					"-p", context.WorkingDir,
-					"-c", nginxConfPath,
-				})
+			}
+
+			fpmReload := false
+			if shouldEnableReload && exists {
+				watchRestart("fpm", filepath.Join(context.WorkingDir, ".php.fpm.d"))
+				fpmReload = true
 			} else if shouldEnableReload && !exists {
-				logger.Debug.Subprocess("NGINX configuration will not be reloadable since .nginx.conf.d folder not found")
+				logger.Subprocess("FPM will not be reloadable since .php.fpm.d folder not found")
+			}
+
+			// FPM gracefully reloads when the ini files that PHP reads change
+			var iniWatch []WatchPath
+			if shouldEnableReload {
+				iniDirs, err := IniDirs(context.WorkingDir, phprcPath)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+
+				for _, dir := range iniDirs {
+					iniWatch = append(iniWatch, WatchPath{Path: dir, Signal: "SIGUSR2"})
+				}
+			}
+			fpmProc.Watch = append(fpmProc.Watch, iniWatch...)
+
+			// Changes to the app source gracefully reload FPM, so that opcache
+			// is flushed
+			if shouldEnableReload && sourceReload.Enabled {
+				fpmProc.Watch = append(fpmProc.Watch, sourceReload.WatchPath(context.WorkingDir))
+			} else if sourceReload.Enabled {
+				logger.Subprocess("FPM will not reload on source changes since live reload is not enabled")
+			}
+			fpmProc.ReloadSignal = "SIGUSR2"
+			fpmProc.StopSignal = "SIGQUIT"
+			fpmStartupTimeout, err := ReadStartupTimeout("fpm")
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			fpmProc.HealthCheck, err = fpmHealthCheck(fpmStartupTimeout != "")
+			if err != nil {
+				return packit.BuildResult{}, err
 			}
 
-			procs.Add("nginx", serverProc)
-			logger.Subprocess("Nginx: %s %v", serverProc.Command, strings.Join(serverProc.Args, " "))
+			err = add("fpm", fpmProc, fpmReload)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+			logger.Subprocess("FPM: %s %v", fpmProc.Command, strings.Join(fpmProc.Args, " "))
+
+			pools, err := ReadFpmPools(context.WorkingDir)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			var poolWorkers int
+			for _, pool := range pools {
+				title := strings.ToUpper(pool.Name)
+				configChecks = append(configChecks, configCheck{title, NewProc("php-fpm", []string{"-t", "-y", pool.ConfigPath, "-c", phprcPath})})
+
+				poolProc := NewProc("php-fpm", []string{"-y", pool.ConfigPath, "-c", phprcPath})
+				poolProc.ReloadSignal = "SIGUSR2"
+				poolProc.StopSignal = "SIGQUIT"
+
+				address, err := pool.ListenAddress()
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+				if address != "" {
+					ping, err := pool.PingPath()
+					if err != nil {
+						return packit.BuildResult{}, err
+					}
+					poolProc.HealthCheck = &HealthCheck{Socket: address, Ping: ping}
+				}
+
+				if shouldEnableReload {
+					poolProc.Watch = append([]WatchPath{{Path: pool.ConfigPath, Signal: "SIGUSR2"}}, iniWatch...)
+				}
+
+				if shouldAutosizeFpm {
+					children, err := pool.MaxChildren()
+					if err != nil {
+						return packit.BuildResult{}, err
+					}
+					poolWorkers += children
+				}
+
+				err = add(pool.Name, poolProc, false)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+				logger.Subprocess("%s: %s %v", title, poolProc.Command, strings.Join(poolProc.Args, " "))
+			}
+
+			// The workers of the additional pools are kept out of the memory
+			// that the fpm-sizer splits across the pools of $PHP_FPM_PATH
+			if poolWorkers > 0 {
+				launchDefaults[FpmPoolWorkersEnv] = strconv.Itoa(poolWorkers)
+			}
 		}
 
-		// FPM Case
-		fpmConfPath, ok := os.LookupEnv("PHP_FPM_PATH")
-		if !ok || fpmConfPath == "" {
-			return packit.BuildResult{}, errors.New("failed to lookup $PHP_FPM_PATH")
-		}
-
-		phprcPath, ok := os.LookupEnv("PHPRC")
-		if !ok || phprcPath == "" {
-			return packit.BuildResult{}, errors.New("failed to lookup $PHPRC path for FPM")
-		}
-		fpmProc := NewProc("php-fpm", []string{"-y", fpmConfPath, "-c", phprcPath})
-
-		if exists, err := fs.Exists(filepath.Join(context.WorkingDir, ".php.fpm.d")); err != nil {
-			return packit.BuildResult{}, err
-		} else if shouldEnableReload && exists {
-			// FPM should reload configuration when it receives SIGUSR2
-			// https://linux.die.net/man/8/php-fpm
-			fpmProc = NewProc("watchexec", []string{
-				"--watch", "/workspace/.php.fpm.d",
-				"--on-busy-update", "signal",
-				"--signal", "SIGUSR2",
-				"--shell", "none",
-				"--", "php-fpm",
-				"-y", fpmConfPath,
-				"-c", phprcPath,
+		backgroundWorkers, err := workers(context.WorkingDir)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		for _, worker := range backgroundWorkers {
+			err = add(worker.name, worker.proc, false)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+			logger.Subprocess("%s: %s %v", strings.ToUpper(worker.name), worker.proc.Command, strings.Join(worker.proc.Args, " "))
+		}
+
+		if task, ok := initTask(); ok {
+			err = add(InitTaskName, task, false)
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+			logger.Subprocess("%s: %s %v", strings.ToUpper(InitTaskName), task.Command, strings.Join(task.Args, " "))
+		}
+
+		schedules, err := ReadSchedules(schedulesPath(context.WorkingDir))
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		for _, name := range sortedScheduleNames(schedules) {
+			schedule := schedules[name]
+			err = schedule.Validate()
+			if err != nil {
+				return packit.BuildResult{}, fmt.Errorf("invalid schedule %s: %w", name, err)
+			}
+
+			resolved.AddSchedule(name, schedule)
+			procs.AddSchedule(name, schedule)
+			logger.Subprocess("CRON %s: %s %s %v", name, schedule.Cron, schedule.Command, strings.Join(schedule.Args, " "))
+		}
+
+		if shouldCheckConfig {
+			logger.Break()
+			logger.Process("Checking configuration syntax:")
+			for _, configCheck := range configChecks {
+				err = configChecker.Check(configCheck.name, configCheck.check)
+				if err != nil {
+					return packit.BuildResult{}, err
+				}
+			}
+		}
+
+		procmgrChecksum, err := fs.NewChecksumCalculator().Sum(filepath.Join(context.CNBPath, "bin", "procmgr-binary"))
+		if err != nil {
+			return packit.BuildResult{}, fmt.Errorf("failed to checksum procmgr-binary: %w", err)
+		}
+
+		binaryChecksums := []string{procmgrChecksum}
+		for _, binary := range execD {
+			binaryChecksum, err := fs.NewChecksumCalculator().Sum(filepath.Join(context.CNBPath, "bin", binary))
+			if err != nil {
+				return packit.BuildResult{}, fmt.Errorf("failed to checksum %s: %w", binary, err)
+			}
+			binaryChecksums = append(binaryChecksums, binaryChecksum)
+		}
+
+		launchEnv := map[string]string{}
+		if !server.UsesFpm() {
+			// Servers that are not configured by another buildpack listen on
+			// $PORT, which is expanded by the procmgr-binary at launch time.
+			launchEnv["PORT"] = "8080"
+		}
+		maps.Copy(launchEnv, launchDefaults)
+
+		// The procmgr-binary of the reload-web launch process watches the
+		// paths that send a process a signal
+		reloadEnv := map[string]string{}
+		if signalWatches {
+			reloadEnv[LiveReloadEnv] = "true"
+		}
+
+		checksum, err := procsChecksum(resolved, shouldEnableReload, launchEnv, reloadEnv, binaryChecksums...)
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+
+		label, err := json.Marshal(table)
+		if err != nil {
+			//untested
+			return packit.BuildResult{}, err
+		}
+
+		web := packit.Process{
+			Type:    "web",
+			Command: "procmgr-binary",
+			Args:    []string{filepath.Join(layer.Path, "procs.yml")},
+			Default: true,
+			Direct:  true,
+		}
+
+		processes := []packit.Process{web}
+		if len(reloadSpec.WatchPaths) > 0 {
+			nonReloadable, reloadable := reloader.TransformReloadableProcesses(web, reloadSpec)
+			processes = []packit.Process{nonReloadable, reloadable}
+		} else if signalWatches {
+			// Without paths that restart the processes, the reload-web process
+			// only differs from the web one by its environment
+			reloadable := web
+			reloadable.Type = "reload-web"
+			web.Default = false
+			processes = []packit.Process{web, reloadable}
+		}
+
+		if len(liveWatches) > 0 {
+			logger.Break()
+			logger.Process("Watching for live reload:")
+			for _, watch := range liveWatches {
+				logger.Subprocess("%s: %s (%s)", strings.ToUpper(watch.process), watch.path, watch.action)
+			}
+		}
+
+		// Each worker can also be run on its own, for example to scale it
+		// separately from the server
+		for _, worker := range backgroundWorkers {
+			processes = append(processes, packit.Process{
+				Type:    worker.name,
+				Command: worker.proc.Command,
+				Args:    worker.proc.Args,
+				Direct:  true,
 			})
-		} else if shouldEnableReload && !exists {
-			logger.Subprocess("FPM will not be reloadable since .php.fpm.d folder not found")
 		}
 
-		procs.Add("fpm", fpmProc)
-		logger.Subprocess("FPM: %s %v", fpmProc.Command, strings.Join(fpmProc.Args, " "))
+		launch := packit.LaunchMetadata{
+			Processes: processes,
+			Labels: map[string]string{
+				ProcessesLabel: string(label),
+			},
+		}
+
+		// The layer SBOM describes the procmgr-binary in the layer, which is
+		// the one of the buildpack, since the contents of the layer are not
+		// restored when it is reused
+		generateSBOM := func() error {
+			procmgrPath := filepath.Join(context.CNBPath, "bin", "procmgr-binary")
+			logger.Debug.Process("Generating SBOM for %s", procmgrPath)
+			logger.Debug.Break()
+
+			var err error
+			layer.SBOM, err = sbomGenerator.Generate(procmgrPath, context.BuildpackInfo.SBOMFormats)
+			if err != nil {
+				return fmt.Errorf("failed to generate SBOM for procmgr-binary: %w", err)
+			}
+
+			return nil
+		}
+
+		if sha, ok := layer.Metadata["procs_sha"].(string); ok && sha == checksum {
+			logger.Process("Reusing cached layer %s", layer.Path)
+			logger.Break()
+
+			// The contents of a launch layer, its launch environment and exec.d
+			// binaries included, are reused from the previous image. Only its
+			// metadata is restored, so nothing may be written into it.
+			layer.Launch = true
+			err = generateSBOM()
+			if err != nil {
+				return packit.BuildResult{}, err
+			}
+
+			logger.LaunchProcesses(processes)
+
+			return packit.BuildResult{
+				Layers: []packit.Layer{layer},
+				Launch: launch,
+			}, nil
+		}
+
+		layer, err = layer.Reset()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
+		layer.Launch = true
+		layer.Metadata = map[string]interface{}{
+			"procs_sha": checksum,
+			"processes": table,
+		}
+
+		for name, value := range launchEnv {
+			layer.LaunchEnv.Default(name, value)
+		}
+
+		if len(reloadEnv) > 0 {
+			processEnv := packit.Environment{}
+			for name, value := range reloadEnv {
+				processEnv.Default(name, value)
+			}
+			layer.ProcessLaunchEnv[processes[1].Type] = processEnv
+		}
+
+		for _, binary := range execD {
+			layer.ExecD = append(layer.ExecD, filepath.Join(context.CNBPath, "bin", binary))
+		}
 
 		// Write the process file
 		logger.Debug.Subprocess("Writing process file to %s", filepath.Join(layer.Path, "procs.yml"))
@@ -165,24 +632,47 @@ func Build(procs ProcMgr, logger scribe.Emitter, reloader Reloader) packit.Build
 
 		err = fs.Copy(filepath.Join(context.CNBPath, "bin", "procmgr-binary"), filepath.Join(layer.Path, "bin", "procmgr-binary"))
 		if err != nil {
+			//untested
 			return packit.BuildResult{}, fmt.Errorf("failed to copy procmgr-binary into layer: %w", err)
 		}
 
-		processes := []packit.Process{{
-			Type:    "web",
-			Command: "procmgr-binary",
-			Args:    []string{filepath.Join(layer.Path, "procs.yml")},
-			Default: true,
-			Direct:  true,
-		}}
+		err = generateSBOM()
+		if err != nil {
+			return packit.BuildResult{}, err
+		}
 
 		logger.LaunchProcesses(processes)
 
 		return packit.BuildResult{
 			Layers: []packit.Layer{layer},
-			Launch: packit.LaunchMetadata{
-				Processes: processes,
-			},
+			Launch: launch,
 		}, nil
 	}
 }
+
+// procsChecksum returns a checksum of everything that ends up in the php-start
+// layer: the resolved processes, whether live reload is enabled, the launch
+// environment of the layer and of the reload-web launch process, and the
+// binaries, such as the procmgr-binary, that are copied into it.
+func procsChecksum(procs Procs, reload bool, launchEnv, reloadEnv map[string]string, binaryChecksums ...string) (string, error) {
+	content, err := yaml.Marshal(procs)
+	if err != nil {
+		//untested
+		return "", err
+	}
+
+	env, err := yaml.Marshal(map[string]map[string]string{"launch": launchEnv, "reload": reloadEnv})
+	if err != nil {
+		//untested
+		return "", err
+	}
+
+	sum := sha256.New()
+	_, err = fmt.Fprintf(sum, "%s\n%t\n%s\n%s", content, reload, env, strings.Join(binaryChecksums, "\n"))
+	if err != nil {
+		//untested
+		return "", err
+	}
+
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
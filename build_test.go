@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/paketo-buildpacks/libreload-packit"
 	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/sbom"
 	"github.com/paketo-buildpacks/packit/v2/scribe"
 	phpstart "github.com/paketo-buildpacks/php-start"
 	"github.com/paketo-buildpacks/php-start/fakes"
@@ -25,10 +28,12 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		workingDir string
 		cnbDir     string
 
-		buffer    *bytes.Buffer
-		procMgr   *fakes.ProcMgr
-		reloader  *fakes.Reloader
-		processes map[string]phpstart.Proc
+		buffer        *bytes.Buffer
+		procMgr       *fakes.ProcMgr
+		reloader      *fakes.Reloader
+		configChecker *fakes.ConfigChecker
+		sbomGenerator *fakes.SBOMGenerator
+		processes     map[string]phpstart.Proc
 
 		buildContext packit.BuildContext
 		build        packit.BuildFunc
@@ -41,12 +46,19 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 		Expect(os.Mkdir(filepath.Join(cnbDir, "bin"), 0700)).To(Succeed())
 		Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "procmgr-binary"), []byte{}, 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "port-binder"), []byte{}, 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "fpm-sizer"), []byte{}, 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "server-tuner"), []byte{}, 0644)).To(Succeed())
 
 		buffer = bytes.NewBuffer(nil)
 		logEmitter := scribe.NewEmitter(buffer).WithLevel("DEBUG")
 
 		procMgr = &fakes.ProcMgr{}
 		reloader = &fakes.Reloader{}
+		reloader.TransformReloadableProcessesCall.Returns.NonReloadable = packit.Process{Type: "web", Command: "procmgr-binary"}
+		reloader.TransformReloadableProcessesCall.Returns.Reloadable = packit.Process{Type: "reload-web", Command: "watchexec", Default: true}
+		configChecker = &fakes.ConfigChecker{}
+		sbomGenerator = &fakes.SBOMGenerator{}
 		processes = map[string]phpstart.Proc{}
 		procMgr.AddCall.Stub = func(procName string, newProc phpstart.Proc) {
 			processes[procName] = newProc
@@ -65,7 +77,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			},
 			Layers: packit.Layers{Path: layersDir},
 		}
-		build = phpstart.Build(procMgr, logEmitter, reloader)
+		build = phpstart.Build(procMgr, logEmitter, reloader, phpstart.DefaultServerRegistry(), configChecker, sbomGenerator)
 	})
 
 	context("[HTTPD] the PHP_HTTPD, PHP_FPM_PATH, and PHPRC env vars are set", func() {
@@ -100,6 +112,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 						"-c",
 						"phprc-path",
 					},
+					ReloadSignal: "SIGUSR2",
+					StopSignal:   "SIGQUIT",
 				},
 				"httpd": {
 					Command: "httpd",
@@ -110,6 +124,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 						"start",
 						"-DFOREGROUND",
 					},
+					ReloadSignal: "SIGHUP",
+					StopSignal:   "SIGWINCH",
 				},
 			}
 			Expect(processes).To(Equal(expectedProcesses))
@@ -120,6 +136,73 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			Expect(buffer.String()).To(ContainSubstring("HTTPD: httpd -f httpd-conf-path -k start -DFOREGROUND"))
 		})
 
+		it("publishes the process table in the layer metadata, an image label and the SBOM", func() {
+			buildContext.BuildpackInfo.SBOMFormats = []string{sbom.CycloneDXFormat, sbom.SPDXFormat, sbom.SyftFormat}
+
+			var err error
+			sbomGenerator.GenerateCall.Returns.SBOMFormatter, err = sbom.SBOM{}.InFormats(buildContext.BuildpackInfo.SBOMFormats...)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			table := phpstart.ProcessTable{
+				SchemaVersion: phpstart.ProcsSchemaVersion,
+				Server:        "httpd",
+				Processes: map[string]phpstart.ProcessTableEntry{
+					"fpm": {
+						Command: "php-fpm",
+						Args:    []string{"-y", "fpm-conf-path", "-c", "phprc-path"},
+					},
+					"httpd": {
+						Command: "httpd",
+						Args:    []string{"-f", "httpd-conf-path", "-k", "start", "-DFOREGROUND"},
+					},
+				},
+			}
+
+			layer := result.Layers[0]
+			Expect(layer.Metadata).To(HaveKeyWithValue("processes", table))
+
+			Expect(result.Launch.Labels).To(HaveKey(phpstart.ProcessesLabel))
+			Expect(result.Launch.Labels[phpstart.ProcessesLabel]).To(MatchJSON(`{
+				"procs_schema_version": 1,
+				"server": "httpd",
+				"processes": {
+					"fpm": {"command": "php-fpm", "args": ["-y", "fpm-conf-path", "-c", "phprc-path"], "reload": false},
+					"httpd": {"command": "httpd", "args": ["-f", "httpd-conf-path", "-k", "start", "-DFOREGROUND"], "reload": false}
+				}
+			}`))
+
+			Expect(sbomGenerator.GenerateCall.Receives.Path).To(Equal(filepath.Join(cnbDir, "bin", "procmgr-binary")))
+			Expect(sbomGenerator.GenerateCall.Receives.Formats).To(Equal([]string{sbom.CycloneDXFormat, sbom.SPDXFormat, sbom.SyftFormat}))
+			Expect(layer.SBOM.Formats()).To(HaveLen(3))
+			var extensions []string
+			for _, format := range layer.SBOM.Formats() {
+				extensions = append(extensions, format.Extension)
+			}
+			Expect(extensions).To(Equal([]string{"cdx.json", "spdx.json", "syft.json"}))
+		})
+
+		it("logs why composer-packages is not required", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("Not requiring composer-packages: no composer.json found"))
+		})
+
+		context("when the app has a composer.json and composer.lock", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte("{}"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "composer.lock"), []byte("{}"), 0644)).To(Succeed())
+			})
+
+			it("logs why composer-packages is required", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).To(ContainSubstring("Requiring composer-packages at launch: composer.json and composer.lock found"))
+			})
+		})
+
 		context("when live reload is enabled", func() {
 			it.Before(func() {
 				reloader.ShouldEnableLiveReloadCall.Returns.Bool = true
@@ -131,48 +214,196 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 					Expect(os.MkdirAll(filepath.Join(workingDir, ".httpd.conf.d"), os.ModePerm)).To(Succeed())
 				})
 
-				it("should add watchexec processes to the process file", func() {
+				it("should add a reload-web launch process that watches them", func() {
 					result, err := build(buildContext)
 					Expect(err).NotTo(HaveOccurred())
 
 					expectedProcesses := map[string]phpstart.Proc{
 						"fpm": {
-							Command: "watchexec",
-							Args: []string{
-								"--watch", "/workspace/.php.fpm.d",
-								"--on-busy-update", "signal",
-								"--signal", "SIGUSR2",
-								"--shell", "none",
-								"--", "php-fpm",
-								"-y", "fpm-conf-path",
-								"-c", "phprc-path",
-							},
+							Command:      "php-fpm",
+							Args:         []string{"-y", "fpm-conf-path", "-c", "phprc-path"},
+							ReloadSignal: "SIGUSR2",
+							StopSignal:   "SIGQUIT",
 						},
 						"httpd": {
-							Command: "watchexec",
-							Args: []string{
-								"--watch", "/workspace/.httpd.conf.d",
-								"--on-busy-update", "signal",
-								"--signal", "SIGHUP",
-								"--shell", "none",
-								"--", "httpd",
-								"-f", "httpd-conf-path",
-								"-k", "start",
-								"-DFOREGROUND",
-							},
+							Command:      "httpd",
+							Args:         []string{"-f", "httpd-conf-path", "-k", "start", "-DFOREGROUND"},
+							ReloadSignal: "SIGHUP",
+							StopSignal:   "SIGWINCH",
 						},
 					}
 					Expect(processes).To(Equal(expectedProcesses))
 
-					Expect(result.Launch.Processes).To(ConsistOf(packit.Process{
+					Expect(reloader.TransformReloadableProcessesCall.CallCount).To(Equal(1))
+					Expect(reloader.TransformReloadableProcessesCall.Receives.OriginalProcess).To(Equal(packit.Process{
 						Type:    "web",
 						Command: "procmgr-binary",
 						Args:    []string{filepath.Join(layersDir, "php-start", "procs.yml")},
 						Default: true,
 						Direct:  true,
 					}))
+					Expect(reloader.TransformReloadableProcessesCall.Receives.Spec).To(Equal(libreload.ReloadableProcessSpec{
+						WatchPaths: []string{
+							filepath.Join(workingDir, ".httpd.conf.d"),
+							filepath.Join(workingDir, ".php.fpm.d"),
+						},
+					}))
+
+					Expect(result.Launch.Processes).To(Equal([]packit.Process{
+						reloader.TransformReloadableProcessesCall.Returns.NonReloadable,
+						reloader.TransformReloadableProcessesCall.Returns.Reloadable,
+					}))
+
+					table := result.Layers[0].Metadata["processes"].(phpstart.ProcessTable)
+					Expect(table.Processes["fpm"].Reload).To(BeTrue())
+					Expect(table.Processes["httpd"].Reload).To(BeTrue())
+
+					Expect(buffer.String()).To(ContainSubstring("Watching for live reload:"))
+					Expect(buffer.String()).To(ContainSubstring(filepath.Join(workingDir, ".httpd.conf.d")))
+				})
+			})
+
+			context("when the ini directories exist", func() {
+				var phprcDir, scanDir string
+
+				it.Before(func() {
+					phprcDir = t.TempDir()
+					scanDir = t.TempDir()
+					t.Setenv("PHPRC", phprcDir)
+					t.Setenv("PHP_INI_SCAN_DIR", strings.Join([]string{scanDir, filepath.Join(scanDir, "missing"), phprcDir}, string(os.PathListSeparator)))
+					Expect(os.MkdirAll(filepath.Join(workingDir, ".php.ini.d"), os.ModePerm)).To(Succeed())
+				})
+
+				it("gracefully reloads FPM when they change", func() {
+					result, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes["fpm"].Watch).To(Equal([]phpstart.WatchPath{
+						{Path: phprcDir, Signal: "SIGUSR2"},
+						{Path: scanDir, Signal: "SIGUSR2"},
+						{Path: filepath.Join(workingDir, ".php.ini.d"), Signal: "SIGUSR2"},
+					}))
+					Expect(result.Layers[0].ProcessLaunchEnv).To(HaveKey("reload-web"))
+
+					Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("FPM: %s (SIGUSR2)", phprcDir)))
+					Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("FPM: %s (SIGUSR2)", scanDir)))
+					Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("FPM: %s (SIGUSR2)", filepath.Join(workingDir, ".php.ini.d"))))
+				})
+			})
+
+			context("when extra watch paths are set for a process", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_RELOAD_WATCH_FPM", ".php.ini.d:SIGUSR2, config")
+					t.Setenv("BP_PHP_RELOAD_WATCH_HTTPD", "/etc/httpd/extra:SIGHUP")
+				})
+
+				it("restarts or signals the process when they change", func() {
+					result, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes["fpm"].Watch).To(Equal([]phpstart.WatchPath{
+						{Path: filepath.Join(workingDir, ".php.ini.d"), Signal: "SIGUSR2"},
+					}))
+					Expect(processes["httpd"].Watch).To(Equal([]phpstart.WatchPath{
+						{Path: "/etc/httpd/extra", Signal: "SIGHUP"},
+					}))
+					Expect(reloader.TransformReloadableProcessesCall.Receives.Spec.WatchPaths).To(Equal([]string{
+						filepath.Join(workingDir, "config"),
+					}))
+
+					layer := result.Layers[0]
+					Expect(layer.ProcessLaunchEnv).To(HaveKeyWithValue("reload-web", packit.Environment{
+						"PHP_START_LIVE_RELOAD.default": "true",
+					}))
+
+					table := layer.Metadata["processes"].(phpstart.ProcessTable)
+					Expect(table.Processes["fpm"].Reload).To(BeTrue())
+					Expect(table.Processes["httpd"].Reload).To(BeTrue())
+
+					Expect(buffer.String()).To(ContainSubstring("HTTPD: /etc/httpd/extra (SIGHUP)"))
+					Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("FPM: %s (restart)", filepath.Join(workingDir, "config"))))
+					Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("FPM: %s (SIGUSR2)", filepath.Join(workingDir, ".php.ini.d"))))
+				})
 
+				context("when no path restarts the processes", func() {
+					it.Before(func() {
+						t.Setenv("BP_PHP_RELOAD_WATCH_FPM", ".php.ini.d:SIGUSR2")
+					})
+
+					it("adds a reload-web launch process that only signals them", func() {
+						result, err := build(buildContext)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(reloader.TransformReloadableProcessesCall.CallCount).To(Equal(0))
+						Expect(result.Launch.Processes[:2]).To(Equal([]packit.Process{
+							{
+								Type:    "web",
+								Command: "procmgr-binary",
+								Args:    []string{filepath.Join(layersDir, "php-start", "procs.yml")},
+								Direct:  true,
+							},
+							{
+								Type:    "reload-web",
+								Command: "procmgr-binary",
+								Args:    []string{filepath.Join(layersDir, "php-start", "procs.yml")},
+								Default: true,
+								Direct:  true,
+							},
+						}))
+					})
+				})
+
+				context("when the signal is not supported", func() {
+					it.Before(func() {
+						t.Setenv("BP_PHP_RELOAD_WATCH_FPM", ".php.ini.d:SIGBOGUS")
+					})
+
+					it("returns an error", func() {
+						_, err := build(buildContext)
+						Expect(err).To(MatchError(`failed to parse BP_PHP_RELOAD_WATCH_FPM value .php.ini.d:SIGBOGUS: unsupported signal "SIGBOGUS"`))
+					})
+				})
+			})
+
+			context("when BP_PHP_SOURCE_RELOAD is true", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_SOURCE_RELOAD", "true")
+				})
+
+				it("gracefully reloads FPM when the app source changes", func() {
+					result, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes["fpm"].Watch).To(Equal([]phpstart.WatchPath{{
+						Path:    workingDir,
+						Signal:  "SIGUSR2",
+						Include: []string{"**/*.php"},
+						Ignore:  []string{"vendor/**", "var/cache/**"},
+					}}))
 					Expect(reloader.TransformReloadableProcessesCall.CallCount).To(Equal(0))
+
+					Expect(result.Launch.Processes).To(HaveLen(2))
+					Expect(result.Launch.Processes[1].Type).To(Equal("reload-web"))
+
+					table := result.Layers[0].Metadata["processes"].(phpstart.ProcessTable)
+					Expect(table.Processes["fpm"].Reload).To(BeTrue())
+					Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("FPM: %s (SIGUSR2)", workingDir)))
+				})
+
+				context("when the included and excluded globs are configured", func() {
+					it.Before(func() {
+						t.Setenv("BP_PHP_SOURCE_RELOAD_INCLUDE", "src/**/*.php, templates/**")
+						t.Setenv("BP_PHP_SOURCE_RELOAD_EXCLUDE", "storage/**, node_modules/**")
+					})
+
+					it("watches those globs", func() {
+						_, err := build(buildContext)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(processes["fpm"].Watch).To(HaveLen(1))
+						Expect(processes["fpm"].Watch[0].Include).To(Equal([]string{"src/**/*.php", "templates/**"}))
+						Expect(processes["fpm"].Watch[0].Ignore).To(Equal([]string{"storage/**", "node_modules/**"}))
+					})
 				})
 			})
 
@@ -190,6 +421,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 								"-c",
 								"phprc-path",
 							},
+							ReloadSignal: "SIGUSR2",
+							StopSignal:   "SIGQUIT",
 						},
 						"httpd": {
 							Command: "httpd",
@@ -200,6 +433,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 								"start",
 								"-DFOREGROUND",
 							},
+							ReloadSignal: "SIGHUP",
+							StopSignal:   "SIGWINCH",
 						},
 					}
 					Expect(processes).To(Equal(expectedProcesses))
@@ -209,6 +444,17 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 
 			context("failure cases", func() {
+				context("when BP_PHP_SOURCE_RELOAD cannot be parsed", func() {
+					it.Before(func() {
+						t.Setenv("BP_PHP_SOURCE_RELOAD", "sometimes")
+					})
+
+					it("returns an error", func() {
+						_, err := build(buildContext)
+						Expect(err).To(MatchError(ContainSubstring("failed to parse BP_PHP_SOURCE_RELOAD value sometimes")))
+					})
+				})
+
 				context("when reloader returns an error", func() {
 					it.Before(func() {
 						reloader.ShouldEnableLiveReloadCall.Returns.Error = errors.New("reload error")
@@ -255,6 +501,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 						"-c",
 						"phprc-path",
 					},
+					ReloadSignal: "SIGUSR2",
+					StopSignal:   "SIGQUIT",
 				},
 				"nginx": {
 					Command: "nginx",
@@ -264,6 +512,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 						"-c",
 						"nginx-conf-path",
 					},
+					ReloadSignal: "SIGHUP",
+					StopSignal:   "SIGQUIT",
 				},
 			}
 			Expect(processes).To(Equal(expectedProcesses))
@@ -274,6 +524,58 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("Nginx: nginx -p %s -c nginx-conf-path", workingDir)))
 		})
 
+		it("does not add the port-binder", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			layer := result.Layers[0]
+			Expect(layer.LaunchEnv).To(BeEmpty())
+			Expect(layer.ExecD).To(BeEmpty())
+		})
+
+		context("when BP_PHP_BIND_PORT is true", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_BIND_PORT", "true")
+			})
+
+			it("binds the $PORT assigned at launch through the port-binder", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes["nginx"].Args).To(Equal([]string{"-p", workingDir, "-c", "${PHP_START_SERVER_CONFIG}"}))
+
+				layer := result.Layers[0]
+				Expect(layer.LaunchEnv).To(Equal(packit.Environment{
+					"PHP_START_LISTEN_DIRECTIVE.default":  "listen",
+					"PHP_START_INCLUDE_DIRECTIVE.default": "include",
+					"PHP_START_SERVER_CONFIG.default":     "nginx-conf-path",
+				}))
+				Expect(layer.ExecD).To(Equal([]string{filepath.Join(cnbDir, "bin", "port-binder")}))
+			})
+
+			context("when the port-binder cannot be read", func() {
+				it.Before(func() {
+					Expect(os.Remove(filepath.Join(cnbDir, "bin", "port-binder"))).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError(ContainSubstring("failed to checksum port-binder:")))
+				})
+			})
+		})
+
+		context("when BP_PHP_BIND_PORT cannot be parsed", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_BIND_PORT", "sometimes")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("failed to parse BP_PHP_BIND_PORT value sometimes")))
+			})
+		})
+
 		context("when live reload is enabled", func() {
 			it.Before(func() {
 				reloader.ShouldEnableLiveReloadCall.Returns.Bool = true
@@ -285,46 +587,26 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 					Expect(os.MkdirAll(filepath.Join(workingDir, ".nginx.conf.d"), os.ModePerm)).To(Succeed())
 				})
 
-				it("should add watchexec processes to the process file", func() {
+				it("should add a reload-web launch process that watches them", func() {
 					result, err := build(buildContext)
 					Expect(err).NotTo(HaveOccurred())
 
-					expectedProcesses := map[string]phpstart.Proc{
-						"fpm": {
-							Command: "watchexec",
-							Args: []string{
-								"--watch", "/workspace/.php.fpm.d",
-								"--on-busy-update", "signal",
-								"--signal", "SIGUSR2",
-								"--shell", "none",
-								"--", "php-fpm",
-								"-y", "fpm-conf-path",
-								"-c", "phprc-path",
-							},
-						},
-						"nginx": {
-							Command: "watchexec",
-							Args: []string{
-								"--watch", "/workspace/.nginx.conf.d",
-								"--on-busy-update", "signal",
-								"--signal", "SIGHUP",
-								"--shell", "none",
-								"--", "nginx",
-								"-p", workingDir,
-								"-c", "nginx-conf-path"},
-						},
-					}
-					Expect(processes).To(Equal(expectedProcesses))
-
-					Expect(result.Launch.Processes).To(ConsistOf(packit.Process{
-						Type:    "web",
-						Command: "procmgr-binary",
-						Args:    []string{filepath.Join(layersDir, "php-start", "procs.yml")},
-						Default: true,
-						Direct:  true,
+					Expect(processes["nginx"]).To(Equal(phpstart.Proc{
+						Command:      "nginx",
+						Args:         []string{"-p", workingDir, "-c", "nginx-conf-path"},
+						ReloadSignal: "SIGHUP",
+						StopSignal:   "SIGQUIT",
 					}))
+					Expect(processes["fpm"].Command).To(Equal("php-fpm"))
 
-					Expect(reloader.TransformReloadableProcessesCall.CallCount).To(Equal(0))
+					Expect(reloader.TransformReloadableProcessesCall.CallCount).To(Equal(1))
+					Expect(reloader.TransformReloadableProcessesCall.Receives.Spec).To(Equal(libreload.ReloadableProcessSpec{
+						WatchPaths: []string{
+							filepath.Join(workingDir, ".nginx.conf.d"),
+							filepath.Join(workingDir, ".php.fpm.d"),
+						},
+					}))
+					Expect(result.Launch.Processes).To(HaveLen(2))
 				})
 			})
 
@@ -342,6 +624,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 								"-c",
 								"phprc-path",
 							},
+							ReloadSignal: "SIGUSR2",
+							StopSignal:   "SIGQUIT",
 						},
 						"nginx": {
 							Command: "nginx",
@@ -351,6 +635,8 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 								"-c",
 								"nginx-conf-path",
 							},
+							ReloadSignal: "SIGHUP",
+							StopSignal:   "SIGQUIT",
 						},
 					}
 					Expect(processes).To(Equal(expectedProcesses))
@@ -374,108 +660,1352 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
-	context("failure cases", func() {
+	context("[CADDY] the PHP_CADDY_PATH, PHP_FPM_PATH, and PHPRC env vars are set", func() {
 		it.Before(func() {
-			t.Setenv("PHP_HTTPD_PATH", "httpd-conf-path")
+			t.Setenv("PHP_CADDY_PATH", "caddy-conf-path")
 			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
 			t.Setenv("PHPRC", "phprc-path")
 		})
 
-		context("the php-start layer cannot be gotten", func() {
-			it.Before(func() {
-				Expect(os.WriteFile(filepath.Join(layersDir, "php-start.toml"), nil, 0000)).To(Succeed())
-			})
+		it("returns a result that starts a Caddy process and an FPM process", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
 
-			it("it returns an error", func() {
-				_, err := build(buildContext)
-				Expect(err).To(MatchError(ContainSubstring("failed to parse layer content metadata:")))
-			})
+			Expect(procMgr.AddCall.CallCount).To(Equal(2))
+			Expect(processes).To(HaveKeyWithValue("caddy", phpstart.Proc{
+				Command:       "caddy",
+				Args:          []string{"run", "--config", "caddy-conf-path"},
+				StopSignal:    "SIGTERM",
+				ReloadCommand: []string{"caddy", "reload", "--config", "caddy-conf-path"},
+			}))
+			Expect(processes).To(HaveKey("fpm"))
+			Expect(buffer.String()).To(ContainSubstring("CADDY: caddy run --config caddy-conf-path"))
 		})
 
-		context("when the php-start layer cannot be reset", func() {
+		context("when live reload is enabled and .caddy.conf.d exists", func() {
 			it.Before(func() {
-				Expect(os.MkdirAll(filepath.Join(layersDir, "php-start", "something"), os.ModePerm)).To(Succeed())
-				Expect(os.Chmod(filepath.Join(layersDir, "php-start"), 0500)).To(Succeed())
+				reloader.ShouldEnableLiveReloadCall.Returns.Bool = true
+				Expect(os.MkdirAll(filepath.Join(workingDir, ".caddy.conf.d"), os.ModePerm)).To(Succeed())
 			})
 
-			it.After(func() {
-				Expect(os.Chmod(filepath.Join(layersDir, "php-start"), os.ModePerm)).To(Succeed())
-			})
+			it("runs caddy reload when the configuration changes", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
 
-			it("returns an error", func() {
-				_, err := build(buildContext)
-				Expect(err).To(MatchError(ContainSubstring("could not remove file")))
-			})
-		})
+				Expect(processes["caddy"].Command).To(Equal("caddy"))
+				Expect(processes["caddy"].Watch).To(Equal([]phpstart.WatchPath{{
+					Path:    filepath.Join(workingDir, ".caddy.conf.d"),
+					Command: []string{"caddy", "reload", "--config", "caddy-conf-path"},
+				}}))
+				Expect(reloader.TransformReloadableProcessesCall.CallCount).To(Equal(0))
 
-		context("neither PHP_HTTPD_PATH nor PHP_NGINX_PATH are set", func() {
-			it.Before(func() {
-				Expect(os.Unsetenv("PHP_HTTPD_PATH")).To(Succeed())
-				Expect(os.Unsetenv("PHP_NGINX_PATH")).To(Succeed())
+				Expect(result.Launch.Processes).To(HaveLen(2))
+				Expect(result.Launch.Processes[1].Type).To(Equal("reload-web"))
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("CADDY: %s (caddy reload --config caddy-conf-path)", filepath.Join(workingDir, ".caddy.conf.d"))))
 			})
+		})
+	})
 
-			it("returns an error", func() {
-				_, err := build(buildContext)
-				Expect(err).To(MatchError(ContainSubstring("need exactly one of: $PHP_HTTPD_PATH or $PHP_NGINX_PATH")))
-			})
+	context("[BUILTIN] the BP_PHP_SERVER env var is set to builtin", func() {
+		it.Before(func() {
+			t.Setenv("BP_PHP_SERVER", "builtin")
 		})
 
-		context("both PHP_HTTPD_PATH and PHP_NGINX_PATH are set", func() {
-			it.Before(func() {
-				t.Setenv("PHP_HTTPD_PATH", "some value")
-				t.Setenv("PHP_NGINX_PATH", "some other value")
-			})
+		it("returns a result that starts the PHP built-in web server only", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
 
-			it("returns an error", func() {
-				_, err := build(buildContext)
-				Expect(err).To(MatchError(ContainSubstring("need exactly one of: $PHP_HTTPD_PATH or $PHP_NGINX_PATH")))
-			})
+			Expect(result.Launch.Processes[0]).To(Equal(packit.Process{
+				Type:    "web",
+				Command: "procmgr-binary",
+				Args: []string{
+					filepath.Join(layersDir, "php-start", "procs.yml"),
+				},
+				Default: true,
+				Direct:  true,
+			}))
+
+			Expect(procMgr.AddCall.CallCount).To(Equal(1))
+			Expect(processes).To(Equal(map[string]phpstart.Proc{
+				"builtin": {
+					Command: "php",
+					Args: []string{
+						"-S", "0.0.0.0:${PORT}",
+						"-t", filepath.Join(workingDir, "htdocs"),
+					},
+					StopSignal: "SIGTERM",
+				},
+			}))
+
+			Expect(result.Layers[0].LaunchEnv).To(Equal(packit.Environment{
+				"PORT.default": "8080",
+			}))
+
+			Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("BUILTIN: php -S 0.0.0.0:${PORT} -t %s", filepath.Join(workingDir, "htdocs"))))
 		})
 
-		context("the PHP_FPM_PATH env var is NOT set", func() {
+		context("when BP_PHP_WEB_DIR and BP_PHP_BUILTIN_ROUTER are set", func() {
 			it.Before(func() {
-				Expect(os.Unsetenv("PHP_FPM_PATH")).To(Succeed())
+				t.Setenv("BP_PHP_WEB_DIR", "public")
+				t.Setenv("BP_PHP_BUILTIN_ROUTER", "router.php")
+				Expect(os.WriteFile(filepath.Join(workingDir, "router.php"), nil, 0600)).To(Succeed())
 			})
 
-			it("returns an error", func() {
+			it("serves the configured web directory through the router script", func() {
 				_, err := build(buildContext)
-				Expect(err).To(MatchError(ContainSubstring("failed to lookup $PHP_FPM_PATH")))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes).To(Equal(map[string]phpstart.Proc{
+					"builtin": {
+						Command: "php",
+						Args: []string{
+							"-S", "0.0.0.0:${PORT}",
+							"-t", filepath.Join(workingDir, "public"),
+							filepath.Join(workingDir, "router.php"),
+						},
+						StopSignal: "SIGTERM",
+					},
+				}))
 			})
 		})
 
-		context("the PHP_FPM_PATH env var is set but empty", func() {
-			it.Before(func() {
-				t.Setenv("PHP_FPM_PATH", "")
-			})
+		context("failure cases", func() {
+			context("when the router script does not exist", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_BUILTIN_ROUTER", "missing.php")
+				})
 
-			it("returns an error", func() {
-				_, err := build(buildContext)
-				Expect(err).To(MatchError(ContainSubstring("failed to lookup $PHP_FPM_PATH")))
+				it("returns an error", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError(ContainSubstring("failed to find router script for built-in server:")))
+				})
 			})
 		})
+	})
 
-		context("the PHPRC env var is not set", func() {
+	context("[APP SERVER] an application server is selected", func() {
+		context("when BP_PHP_SERVER is set to roadrunner", func() {
 			it.Before(func() {
-				Expect(os.Unsetenv("PHPRC")).To(Succeed())
+				t.Setenv("BP_PHP_SERVER", "roadrunner")
+				Expect(os.WriteFile(filepath.Join(workingDir, ".rr.yaml"), []byte("http:\n  address: 0.0.0.0:8080\nstatus:\n  address: 127.0.0.1:2114\n"), 0600)).To(Succeed())
 			})
 
-			it("returns an error, since the PHPRC is needed for FPM start command", func() {
-				_, err := build(buildContext)
-				Expect(err).To(MatchError(ContainSubstring("failed to lookup $PHPRC path for FPM")))
+			it("returns a result that starts the app server only", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(procMgr.AddCall.CallCount).To(Equal(1))
+				Expect(processes).To(Equal(map[string]phpstart.Proc{
+					"roadrunner": {
+						Command: "rr",
+						Args: []string{
+							"serve",
+							"-c", filepath.Join(workingDir, ".rr.yaml"),
+							"-w", workingDir,
+						},
+						StopSignal: "SIGTERM",
+						ReloadCommand: []string{
+							"rr", "reset",
+							"-c", filepath.Join(workingDir, ".rr.yaml"),
+							"-w", workingDir,
+						},
+						HealthCheck: &phpstart.HealthCheck{
+							URL: "http://127.0.0.1:2114/health?plugin=http",
+						},
+					},
+				}))
+
+				Expect(result.Layers[0].LaunchEnv).To(Equal(packit.Environment{
+					"PORT.default": "8080",
+				}))
+
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("ROADRUNNER: rr serve -c %s -w %s", filepath.Join(workingDir, ".rr.yaml"), workingDir)))
 			})
-		})
 
-		context("the PHPRC env var is set but empty", func() {
-			it.Before(func() {
-				t.Setenv("PHPRC", "")
+			context("when the .rr.yaml does not enable the status plugin", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, ".rr.yaml"), []byte("http:\n  address: :8080\n"), 0600)).To(Succeed())
+				})
+
+				it("connects to the address of the HTTP plugin", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes["roadrunner"].HealthCheck).To(Equal(&phpstart.HealthCheck{Socket: "127.0.0.1:8080"}))
+				})
 			})
 
-			it("returns an error, since the PHPRC is needed for FPM start command", func() {
-				_, err := build(buildContext)
+			context("when the .rr.yaml does not exist", func() {
+				it.Before(func() {
+					Expect(os.Remove(filepath.Join(workingDir, ".rr.yaml"))).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError(ContainSubstring("failed to read RoadRunner configuration:")))
+				})
+			})
+
+			context("when the .rr.yaml cannot be parsed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, ".rr.yaml"), []byte("%%%"), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse RoadRunner configuration:")))
+				})
+			})
+		})
+
+		context("when the composer.json requires laravel/octane and ext-swoole", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`{"require": {"laravel/octane": "^2.0", "ext-swoole": "*"}}`), 0600)).To(Succeed())
+			})
+
+			it("starts Octane with Swoole", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes).To(Equal(map[string]phpstart.Proc{
+					"swoole": {
+						Command: "php",
+						Args: []string{
+							filepath.Join(workingDir, "artisan"),
+							"octane:start",
+							"--server=swoole",
+							"--host=0.0.0.0",
+							"--port=${PORT}",
+						},
+						StopSignal: "SIGTERM",
+						ReloadCommand: []string{
+							"php", filepath.Join(workingDir, "artisan"), "octane:reload", "--server=swoole",
+						},
+						HealthCheck: &phpstart.HealthCheck{
+							Socket: "127.0.0.1:${PORT}",
+						},
+					},
+				}))
+			})
+
+			context("when BP_PHP_HEALTHCHECK_PATH is set", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_HEALTHCHECK_PATH", "/up")
+				})
+
+				it("probes the path on $PORT", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes["swoole"].HealthCheck).To(Equal(&phpstart.HealthCheck{URL: "http://127.0.0.1:${PORT}/up"}))
+				})
+			})
+
+			context("and a web server config was provided", func() {
+				it.Before(func() {
+					t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+					t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+					t.Setenv("PHPRC", "phprc-path")
+				})
+
+				it("starts the web server and FPM instead", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes).To(HaveKey("nginx"))
+					Expect(processes).To(HaveKey("fpm"))
+					Expect(processes).NotTo(HaveKey("swoole"))
+				})
+			})
+		})
+
+		context("when the composer.json requires laravel/octane and RoadRunner", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`{"require": {"laravel/octane": "^2.0", "spiral/roadrunner-http": "^3.0", "spiral/roadrunner-cli": "^2.5"}}`), 0600)).To(Succeed())
+			})
+
+			it("starts Octane with RoadRunner", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes).To(Equal(map[string]phpstart.Proc{
+					"roadrunner": {
+						Command: "php",
+						Args: []string{
+							filepath.Join(workingDir, "artisan"),
+							"octane:start",
+							"--server=roadrunner",
+							"--host=0.0.0.0",
+							"--port=${PORT}",
+						},
+						StopSignal: "SIGTERM",
+						ReloadCommand: []string{
+							"php", filepath.Join(workingDir, "artisan"), "octane:reload", "--server=roadrunner",
+						},
+						HealthCheck: &phpstart.HealthCheck{
+							Socket: "127.0.0.1:${PORT}",
+						},
+					},
+				}))
+			})
+		})
+
+		context("when the composer.json requires laravel/octane only", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`{"require": {"laravel/octane": "^2.0"}}`), 0600)).To(Succeed())
+				t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+				t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+				t.Setenv("PHPRC", "phprc-path")
+			})
+
+			it("starts the web server and FPM", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes).To(HaveKey("nginx"))
+				Expect(processes).To(HaveKey("fpm"))
+				Expect(processes).NotTo(HaveKey("swoole"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the composer.json cannot be parsed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`%%%`), 0600)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse composer.json")))
+				})
+			})
+		})
+	})
+
+	context("[CUSTOM] a server that was added to the registry is configured", func() {
+		var server *fakes.Server
+
+		it.Before(func() {
+			server = &fakes.Server{}
+			server.NameCall.Returns.String = "some-server"
+			server.DisplayNameCall.Returns.String = "Some Server"
+			server.ConfigEnvVarCall.Returns.String = "PHP_SOME_SERVER_PATH"
+			server.UsesFpmCall.Returns.Bool = true
+			server.StopSignalCall.Returns.String = "SIGQUIT"
+			server.StartCommandCall.Returns.Proc = phpstart.NewProc("some-server", []string{"--config", "some-server-conf-path"})
+
+			t.Setenv("PHP_SOME_SERVER_PATH", "some-server-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+
+			build = phpstart.Build(procMgr, scribe.NewEmitter(buffer), reloader, phpstart.NewServerRegistry(phpstart.NewHttpdServer(), server), configChecker, sbomGenerator)
+		})
+
+		it("starts that server and FPM", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(server.StartCommandCall.Receives.WorkingDir).To(Equal(workingDir))
+			Expect(server.StartCommandCall.Receives.ConfigPath).To(Equal("some-server-conf-path"))
+
+			Expect(processes).To(HaveKeyWithValue("some-server", phpstart.Proc{
+				Command:    "some-server",
+				Args:       []string{"--config", "some-server-conf-path"},
+				StopSignal: "SIGQUIT",
+			}))
+			Expect(processes).To(HaveKey("fpm"))
+			Expect(buffer.String()).To(ContainSubstring("Some Server: some-server --config some-server-conf-path"))
+		})
+	})
+
+	context("[LARAVEL] when the app is a Laravel app", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`{"require": {"laravel/framework": "^11.0"}}`), 0600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "artisan"), []byte{}, 0600)).To(Succeed())
+
+			t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+		})
+
+		it("does not add workers unless they are enabled", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(processes).To(HaveLen(2))
+			Expect(result.Launch.Processes).To(HaveLen(1))
+		})
+
+		context("when the queue worker and scheduler are enabled", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_LARAVEL_QUEUE", "true")
+				t.Setenv("BP_PHP_LARAVEL_SCHEDULER", "true")
+			})
+
+			it("supervises them and adds a launch process for each", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				artisan := filepath.Join(workingDir, "artisan")
+				Expect(processes).To(HaveKeyWithValue("laravel-queue", phpstart.Proc{
+					Command:       "php",
+					Args:          []string{artisan, "queue:work", "--no-interaction"},
+					StopSignal:    "SIGTERM",
+					ReloadCommand: []string{"php", artisan, "queue:restart", "--no-interaction"},
+					Restart:       true,
+				}))
+				Expect(processes).To(HaveKeyWithValue("laravel-scheduler", phpstart.Proc{
+					Command:    "php",
+					Args:       []string{artisan, "schedule:work", "--no-interaction"},
+					StopSignal: "SIGTERM",
+					Restart:    true,
+				}))
+
+				Expect(result.Launch.Processes).To(ContainElements(
+					packit.Process{
+						Type:    "laravel-queue",
+						Command: "php",
+						Args:    []string{artisan, "queue:work", "--no-interaction"},
+						Direct:  true,
+					},
+					packit.Process{
+						Type:    "laravel-scheduler",
+						Command: "php",
+						Args:    []string{artisan, "schedule:work", "--no-interaction"},
+						Direct:  true,
+					},
+				))
+				Expect(buffer.String()).To(ContainSubstring("LARAVEL-QUEUE: php"))
+				Expect(buffer.String()).To(ContainSubstring("LARAVEL-SCHEDULER: php"))
+			})
+
+			context("with queue worker options", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_LARAVEL_QUEUE_CONNECTION", "redis")
+					t.Setenv("BP_PHP_LARAVEL_QUEUE_QUEUES", "high, default")
+					t.Setenv("BP_PHP_LARAVEL_QUEUE_SLEEP", "3")
+					t.Setenv("BP_PHP_LARAVEL_QUEUE_TRIES", "5")
+					t.Setenv("BP_PHP_LARAVEL_QUEUE_MEMORY", "256")
+				})
+
+				it("passes them to queue:work", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes["laravel-queue"].Args).To(Equal([]string{
+						filepath.Join(workingDir, "artisan"), "queue:work", "redis",
+						"--queue=high,default",
+						"--sleep=3",
+						"--tries=5",
+						"--memory=256",
+						"--no-interaction",
+					}))
+				})
+			})
+
+			context("when the app has no artisan file", func() {
+				it.Before(func() {
+					Expect(os.Remove(filepath.Join(workingDir, "artisan"))).To(Succeed())
+				})
+
+				it("does not add workers", func() {
+					_, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes).NotTo(HaveKey("laravel-queue"))
+					Expect(processes).NotTo(HaveKey("laravel-scheduler"))
+				})
+			})
+
+			context("failure cases", func() {
+				context("when a queue worker option is not a number", func() {
+					it.Before(func() {
+						t.Setenv("BP_PHP_LARAVEL_QUEUE_TRIES", "many")
+					})
+
+					it("returns an error", func() {
+						_, err := build(buildContext)
+						Expect(err).To(MatchError(ContainSubstring("failed to parse BP_PHP_LARAVEL_QUEUE_TRIES value many")))
+					})
+				})
+
+				context("when BP_PHP_LARAVEL_QUEUE cannot be parsed", func() {
+					it.Before(func() {
+						t.Setenv("BP_PHP_LARAVEL_QUEUE", "%%%")
+					})
+
+					it("returns an error", func() {
+						_, err := build(buildContext)
+						Expect(err).To(MatchError(ContainSubstring("failed to parse BP_PHP_LARAVEL_QUEUE value %%%")))
+					})
+				})
+			})
+		})
+	})
+
+	context("[SYMFONY] when the app is a Symfony app that uses Messenger", func() {
+		var console string
+
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`{"require": {"symfony/messenger": "^7.0"}}`), 0600)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(workingDir, "bin"), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(workingDir, "bin", "console"), []byte{}, 0600)).To(Succeed())
+			console = filepath.Join(workingDir, "bin", "console")
+
+			t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+			t.Setenv("BP_PHP_SYMFONY_MESSENGER", "true")
+		})
+
+		it("supervises a consumer for the async transport", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(processes).To(HaveKeyWithValue("symfony-messenger", phpstart.Proc{
+				Command:    "php",
+				Args:       []string{console, "messenger:consume", "async", "--no-interaction"},
+				StopSignal: "SIGTERM",
+				Restart:    true,
+			}))
+			Expect(result.Launch.Processes).To(ContainElement(packit.Process{
+				Type:    "symfony-messenger",
+				Command: "php",
+				Args:    []string{console, "messenger:consume", "async", "--no-interaction"},
+				Direct:  true,
+			}))
+		})
+
+		context("with consumer options", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_SYMFONY_MESSENGER_TRANSPORTS", "async,failed")
+				t.Setenv("BP_PHP_SYMFONY_MESSENGER_TIME_LIMIT", "3600")
+				t.Setenv("BP_PHP_SYMFONY_MESSENGER_MEMORY_LIMIT", "128M")
+				t.Setenv("BP_PHP_SYMFONY_MESSENGER_INSTANCES", "2")
+				t.Setenv("BP_PHP_SYMFONY_MESSENGER_STOP_SIGNAL", "SIGINT")
+			})
+
+			it("supervises each instance of the consumer", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				consumer := phpstart.Proc{
+					Command:    "php",
+					Args:       []string{console, "messenger:consume", "async", "failed", "--time-limit=3600", "--memory-limit=128M", "--no-interaction"},
+					StopSignal: "SIGINT",
+					Restart:    true,
+				}
+				Expect(processes).To(HaveKeyWithValue("symfony-messenger-1", consumer))
+				Expect(processes).To(HaveKeyWithValue("symfony-messenger-2", consumer))
+				Expect(processes).NotTo(HaveKey("symfony-messenger"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the instance count is not a positive number", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_SYMFONY_MESSENGER_INSTANCES", "0")
+				})
+
+				it("returns an error", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse BP_PHP_SYMFONY_MESSENGER_INSTANCES value 0")))
+				})
+			})
+
+			context("when the stop signal is not supported", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_SYMFONY_MESSENGER_STOP_SIGNAL", "SIGFOO")
+				})
+
+				it("returns an error", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse BP_PHP_SYMFONY_MESSENGER_STOP_SIGNAL value SIGFOO")))
+				})
+			})
+		})
+	})
+
+	context("[CRON] when the app declares schedules", func() {
+		var schedules map[string]phpstart.Schedule
+
+		it.Before(func() {
+			Expect(os.WriteFile(filepath.Join(workingDir, ".php-cron.yml"), []byte(`schedules:
+  wp-cron:
+    cron: "*/5 * * * *"
+    command: wp
+    args: [cron, event, run, --due-now]
+    timeout: 5m
+`), 0600)).To(Succeed())
+
+			t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+
+			schedules = map[string]phpstart.Schedule{}
+			procMgr.AddScheduleCall.Stub = func(name string, schedule phpstart.Schedule) {
+				schedules[name] = schedule
+			}
+		})
+
+		it("adds them to the process file", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(schedules).To(Equal(map[string]phpstart.Schedule{
+				"wp-cron": {
+					Cron:    "*/5 * * * *",
+					Command: "wp",
+					Args:    []string{"cron", "event", "run", "--due-now"},
+					Timeout: "5m",
+				},
+			}))
+			Expect(buffer.String()).To(ContainSubstring("CRON wp-cron: */5 * * * * wp cron event run --due-now"))
+		})
+
+		context("when BP_PHP_CRON_FILE is set", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_CRON_FILE", "config/cron.yml")
+			})
+
+			it("reads the schedules from that file", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(procMgr.AddScheduleCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("when a schedule is invalid", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, ".php-cron.yml"), []byte("schedules:\n  wp-cron:\n    cron: every minute\n    command: wp\n"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("invalid schedule wp-cron: invalid cron expression")))
+			})
+		})
+	})
+
+	context("when the php-start layer was built with the same inputs", func() {
+		it.Before(func() {
+			t.Setenv("PHP_HTTPD_PATH", "httpd-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+			t.Setenv("BP_PHP_FPM_AUTOSIZE", "true")
+
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			layer := result.Layers[0]
+			Expect(layer.Metadata).To(HaveKeyWithValue("procs_sha", Not(BeEmpty())))
+
+			// Only the metadata of a launch layer is restored
+			Expect(os.RemoveAll(layer.Path)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(layersDir, "php-start.toml"), []byte(fmt.Sprintf("[metadata]\nprocs_sha = %q\n", layer.Metadata["procs_sha"])), 0600)).To(Succeed())
+
+			buffer.Reset()
+			procMgr.WriteFileCall.CallCount = 0
+			sbomGenerator.GenerateCall.CallCount = 0
+		})
+
+		it("reuses the layer as a launch layer", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(result.Layers).To(HaveLen(1))
+			layer := result.Layers[0]
+			Expect(layer.Metadata).To(HaveKey("procs_sha"))
+			Expect(layer.Launch).To(BeTrue())
+			Expect(layer.LaunchEnv).To(BeEmpty())
+			Expect(layer.ProcessLaunchEnv).To(BeEmpty())
+			Expect(layer.ExecD).To(BeEmpty())
+			Expect(layer.Path).NotTo(BeADirectory())
+			Expect(sbomGenerator.GenerateCall.CallCount).To(Equal(1))
+			Expect(sbomGenerator.GenerateCall.Receives.Path).To(Equal(filepath.Join(cnbDir, "bin", "procmgr-binary")))
+
+			Expect(result.Launch.Processes).To(HaveLen(1))
+			Expect(result.Launch.Processes[0].Args).To(Equal([]string{filepath.Join(layersDir, "php-start", "procs.yml")}))
+
+			Expect(procMgr.WriteFileCall.CallCount).To(Equal(0))
+			Expect(buffer.String()).To(ContainSubstring("Reusing cached layer"))
+		})
+
+		context("when live reload was enabled in the previous build as well", func() {
+			it.Before(func() {
+				reloader.ShouldEnableLiveReloadCall.Returns.Bool = true
+				Expect(os.Mkdir(filepath.Join(workingDir, ".php.ini.d"), os.ModePerm)).To(Succeed())
+
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				layer := result.Layers[0]
+				Expect(layer.ProcessLaunchEnv).To(HaveKey("reload-web"))
+				Expect(os.RemoveAll(layer.Path)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(layersDir, "php-start.toml"), []byte(fmt.Sprintf("[metadata]\nprocs_sha = %q\n", layer.Metadata["procs_sha"])), 0600)).To(Succeed())
+
+				buffer.Reset()
+				procMgr.WriteFileCall.CallCount = 0
+			})
+
+			it("reuses the layer without the environment of the reload-web process", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				layer := result.Layers[0]
+				Expect(layer.Launch).To(BeTrue())
+				Expect(layer.LaunchEnv).To(BeEmpty())
+				Expect(layer.ProcessLaunchEnv).To(BeEmpty())
+				Expect(layer.ExecD).To(BeEmpty())
+
+				Expect(result.Launch.Processes).To(HaveLen(2))
+				Expect(result.Launch.Processes[1].Type).To(Equal("reload-web"))
+
+				Expect(procMgr.WriteFileCall.CallCount).To(Equal(0))
+				Expect(buffer.String()).To(ContainSubstring("Reusing cached layer"))
+			})
+		})
+
+		context("when the launch environment changed", func() {
+			it.Before(func() {
+				t.Setenv("PHP_FPM_PATH", "other-fpm-conf-path")
+			})
+
+			it("rebuilds the layer", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers[0].LaunchEnv).To(HaveKeyWithValue("PHP_START_FPM_CONFIG.default", "other-fpm-conf-path"))
+				Expect(procMgr.WriteFileCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).NotTo(ContainSubstring("Reusing cached layer"))
+			})
+		})
+
+		context("when live reload is enabled", func() {
+			it.Before(func() {
+				reloader.ShouldEnableLiveReloadCall.Returns.Bool = true
+			})
+
+			it("rebuilds the layer", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(procMgr.WriteFileCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).NotTo(ContainSubstring("Reusing cached layer"))
+			})
+		})
+
+		context("when the procmgr-binary changed", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cnbDir, "bin", "procmgr-binary"), []byte("new-binary"), 0644)).To(Succeed())
+			})
+
+			it("rebuilds the layer", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(procMgr.WriteFileCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).NotTo(ContainSubstring("Reusing cached layer"))
+			})
+		})
+
+		context("when the start commands changed", func() {
+			it.Before(func() {
+				t.Setenv("PHPRC", "other-phprc-path")
+			})
+
+			it("rebuilds the layer", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(procMgr.WriteFileCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).NotTo(ContainSubstring("Reusing cached layer"))
+			})
+		})
+	})
+
+	context("when BP_PHP_FPM_AUTOSIZE is true", func() {
+		it.Before(func() {
+			t.Setenv("BP_PHP_FPM_AUTOSIZE", "true")
+			t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+		})
+
+		it("sizes the FPM pools at launch through the fpm-sizer", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(processes["fpm"].Args).To(Equal([]string{"-y", "${PHP_START_FPM_CONFIG}", "-c", "phprc-path"}))
+
+			layer := result.Layers[0]
+			Expect(layer.LaunchEnv).To(HaveKeyWithValue("PHP_START_FPM_CONFIG.default", "fpm-conf-path"))
+			Expect(layer.ExecD).To(Equal([]string{filepath.Join(cnbDir, "bin", "fpm-sizer")}))
+		})
+
+		context("when the fpm-sizer cannot be read", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(cnbDir, "bin", "fpm-sizer"))).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("failed to checksum fpm-sizer:")))
+			})
+		})
+	})
+
+	context("when a startup timeout is set without health checks", func() {
+		it.Before(func() {
+			t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+			t.Setenv("BP_PHP_STARTUP_TIMEOUT_FPM", "30s")
+		})
+
+		it("waits for FPM to accept connections", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(processes["fpm"].HealthCheck).To(Equal(&phpstart.HealthCheck{Socket: "127.0.0.1:9000"}))
+			Expect(processes["fpm"].StartupTimeout).To(Equal("30s"))
+			Expect(processes["nginx"].HealthCheck).To(BeNil())
+		})
+
+		context("when the process has no health check", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_STARTUP_TIMEOUT_NGINX", "30s")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("failed to apply BP_PHP_STARTUP_TIMEOUT_NGINX: process nginx has no health check to wait for"))
+			})
+		})
+	})
+
+	context("when health checks are configured", func() {
+		it.Before(func() {
+			t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+			t.Setenv("BP_PHP_HEALTHCHECK_PATH", "/health")
+			t.Setenv("BP_PHP_FPM_PING_PATH", "/ping")
+		})
+
+		it("probes the web server over HTTP and pings FPM over FastCGI", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(processes["nginx"].HealthCheck).To(Equal(&phpstart.HealthCheck{URL: "http://127.0.0.1:${PORT}/health"}))
+			Expect(processes["fpm"].HealthCheck).To(Equal(&phpstart.HealthCheck{Socket: "127.0.0.1:9000", Ping: "/ping"}))
+		})
+
+		context("when BP_PHP_FPM_LISTEN is set", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_FPM_LISTEN", "/tmp/php-fpm.sock")
+			})
+
+			it("pings FPM at that address", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes["fpm"].HealthCheck).To(Equal(&phpstart.HealthCheck{Socket: "/tmp/php-fpm.sock", Ping: "/ping"}))
+			})
+		})
+
+		context("when startup timeouts are set", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_STARTUP_TIMEOUT_FPM", "30s")
+			})
+
+			it("limits the time that the process has to become ready", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes["fpm"].StartupTimeout).To(Equal("30s"))
+				Expect(processes["nginx"].StartupTimeout).To(BeEmpty())
+			})
+
+			context("when a startup timeout is invalid", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_STARTUP_TIMEOUT_NGINX", "soon")
+				})
+
+				it("returns an error", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError(`failed to parse BP_PHP_STARTUP_TIMEOUT_NGINX value soon: invalid startup timeout "soon": must be a positive duration`))
+				})
+			})
+		})
+
+		context("when a health check path does not start with /", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_HEALTHCHECK_PATH", "health")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(`invalid BP_PHP_HEALTHCHECK_PATH value "health": must start with /`))
+			})
+		})
+	})
+
+	context("when log files are set for a process", func() {
+		it.Before(func() {
+			t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+			t.Setenv("BP_PHP_LOG_FILES_FPM", "var/log/php-fpm.slow.log, /tmp/php-fpm.log")
+		})
+
+		it("adds them to the process", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(processes["fpm"].LogFiles).To(Equal([]string{
+				filepath.Join(workingDir, "var", "log", "php-fpm.slow.log"),
+				"/tmp/php-fpm.log",
+			}))
+			Expect(processes["nginx"].LogFiles).To(BeEmpty())
+		})
+	})
+
+	context("when the app has additional FPM pools", func() {
+		var poolPath string
+
+		it.Before(func() {
+			t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+
+			poolPath = filepath.Join(workingDir, ".php.fpm.pools.d", "admin.conf")
+			Expect(os.MkdirAll(filepath.Dir(poolPath), os.ModePerm)).To(Succeed())
+			Expect(os.WriteFile(poolPath, []byte("[admin]\nlisten = 127.0.0.1:9001\nping.path = /ping\n"), 0644)).To(Succeed())
+		})
+
+		it("supervises a php-fpm process for each pool", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(processes["fpm-admin"]).To(Equal(phpstart.Proc{
+				Command:      "php-fpm",
+				Args:         []string{"-y", poolPath, "-c", "phprc-path"},
+				ReloadSignal: "SIGUSR2",
+				StopSignal:   "SIGQUIT",
+				HealthCheck:  &phpstart.HealthCheck{Socket: "127.0.0.1:9001", Ping: "/ping"},
+			}))
+			Expect(processes).To(HaveKey("fpm"))
+
+			table := result.Layers[0].Metadata["processes"].(phpstart.ProcessTable)
+			Expect(table.Processes).To(HaveKey("fpm-admin"))
+
+			Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("FPM-ADMIN: php-fpm -y %s -c phprc-path", poolPath)))
+		})
+
+		context("when live reload is enabled", func() {
+			it.Before(func() {
+				reloader.ShouldEnableLiveReloadCall.Returns.Bool = true
+			})
+
+			it("gracefully reloads the pool when its configuration changes", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes["fpm-admin"].Watch).To(Equal([]phpstart.WatchPath{
+					{Path: poolPath, Signal: "SIGUSR2"},
+				}))
+				Expect(buffer.String()).To(ContainSubstring(fmt.Sprintf("FPM-ADMIN: %s (SIGUSR2)", poolPath)))
+			})
+		})
+
+		context("when BP_PHP_CONFIG_CHECK is true", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_CONFIG_CHECK", "true")
+			})
+
+			it("checks the configuration of each pool", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(configChecker.CheckCall.CallCount).To(Equal(3))
+				Expect(configChecker.CheckCall.Receives.Name).To(Equal("FPM-ADMIN"))
+				Expect(configChecker.CheckCall.Receives.Check).To(Equal(phpstart.NewProc("php-fpm", []string{"-t", "-y", poolPath, "-c", "phprc-path"})))
+			})
+		})
+
+		context("when BP_PHP_FPM_AUTOSIZE is true", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_FPM_AUTOSIZE", "true")
+				Expect(os.WriteFile(poolPath, []byte("[admin]\nlisten = 127.0.0.1:9001\npm = static\npm.max_children = 3\n"), 0644)).To(Succeed())
+			})
+
+			it("passes the workers of the pools to the fpm-sizer", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Layers[0].LaunchEnv).To(HaveKeyWithValue("PHP_START_FPM_POOL_WORKERS.default", "3"))
+			})
+
+			context("when a pool does not set pm.max_children", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(poolPath, []byte("[admin]\nlisten = 127.0.0.1:9001\n"), 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := build(buildContext)
+					Expect(err).To(MatchError(ContainSubstring("does not set pm.max_children")))
+				})
+			})
+		})
+
+		context("when a listed pool does not exist", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_FPM_POOLS", "missing.conf")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("failed to find FPM pool configuration:")))
+			})
+		})
+	})
+
+	context("when BP_PHP_SERVER_AUTOSIZE is true", func() {
+		it.Before(func() {
+			t.Setenv("BP_PHP_SERVER_AUTOSIZE", "true")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+		})
+
+		context("with Nginx", func() {
+			var nginxConfPath string
+
+			it.Before(func() {
+				nginxConfPath = filepath.Join(t.TempDir(), "nginx.conf")
+				Expect(os.WriteFile(nginxConfPath, []byte("events {}\n"), 0600)).To(Succeed())
+				t.Setenv("PHP_NGINX_PATH", nginxConfPath)
+			})
+
+			it("sets worker_processes through the server-tuner", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes["nginx"].Args).To(Equal([]string{
+					"-p", workingDir,
+					"-c", nginxConfPath,
+					"-g", "worker_processes ${PHP_START_NGINX_WORKER_PROCESSES};",
+				}))
+
+				layer := result.Layers[0]
+				Expect(layer.LaunchEnv).To(HaveKeyWithValue("PHP_START_WORKERS_PER_CPU.default", "PHP_START_NGINX_WORKER_PROCESSES=1"))
+				Expect(layer.LaunchEnv).To(HaveKeyWithValue("PHP_START_NGINX_WORKER_PROCESSES.default", "1"))
+				Expect(layer.ExecD).To(Equal([]string{filepath.Join(cnbDir, "bin", "server-tuner")}))
+			})
+
+			context("when the configuration sets worker_processes", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(nginxConfPath, []byte("worker_processes 4;\nevents {}\n"), 0600)).To(Succeed())
+				})
+
+				it("does not tune the workers", func() {
+					result, err := build(buildContext)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processes["nginx"].Args).To(Equal([]string{
+						"-p", workingDir,
+						"-c", nginxConfPath,
+					}))
+
+					layer := result.Layers[0]
+					Expect(layer.LaunchEnv).NotTo(HaveKey("PHP_START_WORKERS_PER_CPU.default"))
+					Expect(layer.ExecD).To(BeEmpty())
+				})
+			})
+		})
+
+		context("with HTTPD", func() {
+			it.Before(func() {
+				t.Setenv("PHP_HTTPD_PATH", "httpd-conf-path")
+			})
+
+			it("sets ServerLimit and MaxRequestWorkers through the server-tuner", func() {
+				result, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes["httpd"].Args).To(Equal([]string{
+					"-f", "httpd-conf-path",
+					"-k", "start",
+					"-DFOREGROUND",
+					"-c", "ServerLimit ${PHP_START_HTTPD_SERVER_LIMIT}",
+					"-c", "MaxRequestWorkers ${PHP_START_HTTPD_MAX_REQUEST_WORKERS}",
+				}))
+
+				layer := result.Layers[0]
+				Expect(layer.LaunchEnv).To(HaveKeyWithValue("PHP_START_HTTPD_SERVER_LIMIT.default", "1"))
+				Expect(layer.LaunchEnv).To(HaveKeyWithValue("PHP_START_HTTPD_MAX_REQUEST_WORKERS.default", "25"))
+				Expect(layer.LaunchEnv).To(HaveKeyWithValue("PHP_START_WORKERS_PER_CPU.default", "PHP_START_HTTPD_MAX_REQUEST_WORKERS=25,PHP_START_HTTPD_SERVER_LIMIT=1"))
+				Expect(layer.ExecD).To(ContainElement(filepath.Join(cnbDir, "bin", "server-tuner")))
+			})
+		})
+
+		context("when BP_PHP_SERVER_AUTOSIZE cannot be parsed", func() {
+			it.Before(func() {
+				t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+				t.Setenv("BP_PHP_SERVER_AUTOSIZE", "sometimes")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("failed to parse BP_PHP_SERVER_AUTOSIZE value sometimes")))
+			})
+		})
+	})
+
+	context("when BP_PHP_INIT_COMMAND is set", func() {
+		it.Before(func() {
+			t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+			t.Setenv("BP_PHP_INIT_COMMAND", "php artisan migrate --force")
+		})
+
+		it("adds the command as an init task", func() {
+			result, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(processes).To(HaveKeyWithValue("init", phpstart.Proc{
+				Command: "sh",
+				Args:    []string{"-c", "php artisan migrate --force"},
+				Init:    true,
+			}))
+			Expect(result.Launch.Processes).To(HaveLen(1))
+			Expect(buffer.String()).To(ContainSubstring("INIT: sh -c php artisan migrate --force"))
+		})
+
+		context("when the init task has a startup timeout", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_STARTUP_TIMEOUT_INIT", "5m")
+			})
+
+			it("limits the time that the task has to finish", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(processes["init"].StartupTimeout).To(Equal("5m"))
+			})
+		})
+	})
+
+	context("when BP_PHP_CONFIG_CHECK is true", func() {
+		var checks map[string]phpstart.Proc
+
+		it.Before(func() {
+			t.Setenv("BP_PHP_CONFIG_CHECK", "true")
+			t.Setenv("PHP_NGINX_PATH", "nginx-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+
+			checks = map[string]phpstart.Proc{}
+			configChecker.CheckCall.Stub = func(name string, check phpstart.Proc) error {
+				checks[name] = check
+				return nil
+			}
+		})
+
+		it("checks the server and FPM configuration", func() {
+			_, err := build(buildContext)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(configChecker.CheckCall.CallCount).To(Equal(2))
+			Expect(checks).To(Equal(map[string]phpstart.Proc{
+				"NGINX": {
+					Command: "nginx",
+					Args:    []string{"-t", "-p", workingDir, "-c", "nginx-conf-path"},
+				},
+				"FPM": {
+					Command: "php-fpm",
+					Args:    []string{"-t", "-y", "fpm-conf-path", "-c", "phprc-path"},
+				},
+			}))
+			Expect(buffer.String()).To(ContainSubstring("Checking configuration syntax:"))
+		})
+
+		context("when a configuration check fails", func() {
+			it.Before(func() {
+				configChecker.CheckCall.Stub = nil
+				configChecker.CheckCall.Returns.Error = errors.New("failed to check NGINX configuration")
+			})
+
+			it("fails the build", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("failed to check NGINX configuration"))
+				Expect(procMgr.WriteFileCall.CallCount).To(Equal(0))
+			})
+		})
+
+		context("when BP_PHP_CONFIG_CHECK is false", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_CONFIG_CHECK", "false")
+			})
+
+			it("does not check the configuration", func() {
+				_, err := build(buildContext)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(configChecker.CheckCall.CallCount).To(Equal(0))
+				Expect(buffer.String()).NotTo(ContainSubstring("Checking configuration syntax:"))
+			})
+		})
+	})
+
+	context("failure cases", func() {
+		it.Before(func() {
+			t.Setenv("PHP_HTTPD_PATH", "httpd-conf-path")
+			t.Setenv("PHP_FPM_PATH", "fpm-conf-path")
+			t.Setenv("PHPRC", "phprc-path")
+		})
+
+		context("when BP_PHP_SERVER names an unsupported server", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_SERVER", "lighttpd")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring(`unsupported BP_PHP_SERVER value "lighttpd"`)))
+			})
+		})
+
+		context("when BP_PHP_SERVER names a server whose config env var is not set", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_SERVER", "nginx")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("failed to lookup $PHP_NGINX_PATH"))
+			})
+		})
+
+		context("the php-start layer cannot be gotten", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(layersDir, "php-start.toml"), nil, 0000)).To(Succeed())
+			})
+
+			it("it returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("failed to parse layer content metadata:")))
+			})
+		})
+
+		context("when the php-start layer cannot be reset", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(layersDir, "php-start", "something"), os.ModePerm)).To(Succeed())
+				Expect(os.Chmod(filepath.Join(layersDir, "php-start"), 0500)).To(Succeed())
+			})
+
+			it.After(func() {
+				Expect(os.Chmod(filepath.Join(layersDir, "php-start"), os.ModePerm)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("could not remove file")))
+			})
+		})
+
+		context("neither PHP_HTTPD_PATH nor PHP_NGINX_PATH are set", func() {
+			it.Before(func() {
+				Expect(os.Unsetenv("PHP_HTTPD_PATH")).To(Succeed())
+				Expect(os.Unsetenv("PHP_NGINX_PATH")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("need exactly one of: $PHP_HTTPD_PATH or $PHP_NGINX_PATH")))
+			})
+		})
+
+		context("both PHP_HTTPD_PATH and PHP_NGINX_PATH are set", func() {
+			it.Before(func() {
+				t.Setenv("PHP_HTTPD_PATH", "some value")
+				t.Setenv("PHP_NGINX_PATH", "some other value")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("need exactly one of: $PHP_HTTPD_PATH or $PHP_NGINX_PATH")))
+			})
+		})
+
+		context("the PHP_FPM_PATH env var is NOT set", func() {
+			it.Before(func() {
+				Expect(os.Unsetenv("PHP_FPM_PATH")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("failed to lookup $PHP_FPM_PATH")))
+			})
+		})
+
+		context("the PHP_FPM_PATH env var is set but empty", func() {
+			it.Before(func() {
+				t.Setenv("PHP_FPM_PATH", "")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("failed to lookup $PHP_FPM_PATH")))
+			})
+		})
+
+		context("the PHPRC env var is not set", func() {
+			it.Before(func() {
+				Expect(os.Unsetenv("PHPRC")).To(Succeed())
+			})
+
+			it("returns an error, since the PHPRC is needed for FPM start command", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("failed to lookup $PHPRC path for FPM")))
+			})
+		})
+
+		context("the PHPRC env var is set but empty", func() {
+			it.Before(func() {
+				t.Setenv("PHPRC", "")
+			})
+
+			it("returns an error, since the PHPRC is needed for FPM start command", func() {
+				_, err := build(buildContext)
 				Expect(err).To(MatchError(ContainSubstring("failed to lookup $PHPRC path for FPM")))
 			})
 		})
 
+		context("when the SBOM of the procmgr-binary cannot be generated", func() {
+			it.Before(func() {
+				sbomGenerator.GenerateCall.Returns.Error = errors.New("failed to scan")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError("failed to generate SBOM for procmgr-binary: failed to scan"))
+			})
+		})
+
+		context("when BP_PHP_CONFIG_CHECK cannot be parsed", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_CONFIG_CHECK", "%%%")
+			})
+
+			it("returns an error", func() {
+				_, err := build(buildContext)
+				Expect(err).To(MatchError(ContainSubstring("failed to parse BP_PHP_CONFIG_CHECK value %%%")))
+			})
+		})
+
 		context("when the procs.yml cannot be written", func() {
 			it.Before(func() {
 				procMgr.WriteFileCall.Returns.Error = errors.New("failed to write procs.yml")
@@ -487,7 +2017,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 
-		context("when the procmgr binary cannot be copied into layer", func() {
+		context("when the procmgr binary cannot be read", func() {
 			it.Before(func() {
 				Expect(os.Chmod(filepath.Join(cnbDir, "bin", "procmgr-binary"), 0000)).To(Succeed())
 			})
@@ -498,7 +2028,7 @@ func testBuild(t *testing.T, context spec.G, it spec.S) {
 
 			it("returns an error", func() {
 				_, err := build(buildContext)
-				Expect(err).To(MatchError(ContainSubstring("failed to copy procmgr-binary into layer:")))
+				Expect(err).To(MatchError(ContainSubstring("failed to checksum procmgr-binary:")))
 			})
 		})
 	})
@@ -0,0 +1,92 @@
+package phpstart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/fs"
+)
+
+// BuiltinServer runs the PHP built-in web server, serving the app's web
+// directory on $PORT without a separate web server or php-fpm. It is only
+// used when selected with $BP_PHP_SERVER.
+type BuiltinServer struct{}
+
+func NewBuiltinServer() BuiltinServer {
+	return BuiltinServer{}
+}
+
+func (BuiltinServer) Name() string {
+	return Builtin
+}
+
+func (BuiltinServer) DisplayName() string {
+	return "BUILTIN"
+}
+
+func (BuiltinServer) Requirements() []packit.BuildPlanRequirement {
+	return []packit.BuildPlanRequirement{
+		{
+			Name: Php,
+			Metadata: BuildPlanMetadata{
+				Build:  true,
+				Launch: true,
+			},
+		},
+	}
+}
+
+func (BuiltinServer) ConfigEnvVar() string {
+	return ""
+}
+
+func (BuiltinServer) UsesFpm() bool {
+	return false
+}
+
+// StartCommand serves the directory named by $BP_PHP_WEB_DIR, which defaults
+// to htdocs, through the optional router script named by
+// $BP_PHP_BUILTIN_ROUTER.
+func (BuiltinServer) StartCommand(workingDir, _ string) (Proc, error) {
+	docRoot := webDir(workingDir)
+
+	// The built-in server binds to $PORT, which is expanded by the
+	// procmgr-binary at launch time.
+	args := []string{"-S", "0.0.0.0:${PORT}", "-t", docRoot}
+
+	if router, ok := os.LookupEnv("BP_PHP_BUILTIN_ROUTER"); ok && router != "" {
+		routerPath := filepath.Join(workingDir, router)
+		if exists, err := fs.Exists(routerPath); err != nil {
+			return Proc{}, err
+		} else if !exists {
+			return Proc{}, fmt.Errorf("failed to find router script for built-in server: %s", routerPath)
+		}
+		args = append(args, routerPath)
+	}
+
+	return NewProc("php", args), nil
+}
+
+func (BuiltinServer) ReloadSignal() string {
+	return ""
+}
+
+func (BuiltinServer) ReloadDir() string {
+	return ""
+}
+
+func (BuiltinServer) StopSignal() string {
+	return "SIGTERM"
+}
+
+// webDir returns the web root of the app in workingDir, which is named by
+// $BP_PHP_WEB_DIR and defaults to htdocs.
+func webDir(workingDir string) string {
+	if dir, ok := os.LookupEnv("BP_PHP_WEB_DIR"); ok && dir != "" {
+		return filepath.Join(workingDir, dir)
+	}
+
+	return filepath.Join(workingDir, "htdocs")
+}
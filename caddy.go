@@ -0,0 +1,75 @@
+package phpstart
+
+import "github.com/paketo-buildpacks/packit/v2"
+
+// CaddyServer runs Caddy in front of php-fpm, using the configuration
+// provided through $PHP_CADDY_PATH.
+type CaddyServer struct{}
+
+func NewCaddyServer() CaddyServer {
+	return CaddyServer{}
+}
+
+func (CaddyServer) Name() string {
+	return Caddy
+}
+
+func (CaddyServer) DisplayName() string {
+	return "CADDY"
+}
+
+func (CaddyServer) Requirements() []packit.BuildPlanRequirement {
+	return []packit.BuildPlanRequirement{
+		{
+			Name: Caddy,
+			Metadata: BuildPlanMetadata{
+				Launch: true,
+			},
+		},
+		{
+			Name: PhpCaddyConfig,
+			Metadata: BuildPlanMetadata{
+				Build:  true,
+				Launch: true,
+			},
+		},
+	}
+}
+
+func (CaddyServer) ConfigEnvVar() string {
+	return "PHP_CADDY_PATH"
+}
+
+func (CaddyServer) UsesFpm() bool {
+	return true
+}
+
+// StartCommand runs Caddy with the given configuration. Caddy ignores
+// signals for configuration changes, so it is reloaded through the
+// "caddy reload" command, which loads the configuration through the admin
+// API.
+// https://caddyserver.com/docs/command-line#caddy-reload
+func (CaddyServer) StartCommand(workingDir, configPath string) (Proc, error) {
+	proc := NewProc("caddy", []string{"run", "--config", configPath})
+	proc.ReloadCommand = []string{"caddy", "reload", "--config", configPath}
+	return proc, nil
+}
+
+func (CaddyServer) ReloadSignal() string {
+	return ""
+}
+
+func (CaddyServer) ReloadDir() string {
+	return ".caddy.conf.d"
+}
+
+// StopSignal is SIGTERM, which makes Caddy shut down gracefully.
+// https://caddyserver.com/docs/command-line#signals
+func (CaddyServer) StopSignal() string {
+	return "SIGTERM"
+}
+
+// ConfigTestCommand validates the Caddy configuration without starting Caddy.
+func (CaddyServer) ConfigTestCommand(workingDir, configPath string) Proc {
+	return NewProc("caddy", []string{"validate", "--config", configPath})
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitFpmSizer(t *testing.T) {
+	suite := spec.New("cmd/fpm-sizer", spec.Report(report.Terminal{}))
+	suite("Fpm Sizer", testFpmSizer)
+	suite.Run(t)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+)
+
+// The fpm-sizer runs as an exec.d binary when the container starts. It sizes
+// the FPM pools for the memory and CPUs that are available to the container,
+// and points $PHP_START_FPM_CONFIG at a configuration with those settings by
+// writing it to file descriptor 3.
+func main() {
+	err := run(os.NewFile(3, "/dev/fd/3"), os.Stderr, "/sys/fs/cgroup", "/proc/meminfo", os.TempDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to size FPM pools:", err)
+		os.Exit(1)
+	}
+}
+
+func run(output, logs io.Writer, cgroupRoot, meminfoPath, tmpDir string) error {
+	configPath := os.Getenv("PHP_START_FPM_CONFIG")
+	if configPath == "" {
+		return fmt.Errorf("failed to lookup $PHP_START_FPM_CONFIG")
+	}
+
+	workerMemory, err := memoryEnv("PHP_FPM_WORKER_MEMORY", "64M")
+	if err != nil {
+		return err
+	} else if workerMemory == 0 {
+		return fmt.Errorf("failed to parse $PHP_FPM_WORKER_MEMORY value %s: must be more than 0", os.Getenv("PHP_FPM_WORKER_MEMORY"))
+	}
+
+	reservedMemory, err := memoryEnv("PHP_FPM_RESERVED_MEMORY", "64M")
+	if err != nil {
+		return err
+	}
+
+	// The workers of the additional FPM pools are sized by their own
+	// configuration, so their memory is reserved as well
+	if value := os.Getenv(phpstart.FpmPoolWorkersEnv); value != "" {
+		poolWorkers, err := strconv.Atoi(value)
+		if err != nil || poolWorkers < 0 {
+			return fmt.Errorf("failed to parse $%s value %s: must be a number of workers", phpstart.FpmPoolWorkersEnv, value)
+		}
+
+		reservedMemory += int64(poolWorkers) * workerMemory
+		fmt.Fprintf(logs, "Reserving memory for %d workers of additional FPM pools\n", poolWorkers)
+	}
+
+	limits, err := phpstart.ReadContainerLimits(cgroupRoot, meminfoPath)
+	if err != nil {
+		return err
+	}
+
+	size := phpstart.SizeFpmPool(limits, workerMemory, reservedMemory)
+	fmt.Fprintf(logs, "Sizing FPM pools for %d MiB of memory and %.2f CPUs: pm.max_children = %d, pm.start_servers = %d, pm.min_spare_servers = %d, pm.max_spare_servers = %d across all pools\n",
+		limits.Memory>>20, limits.CPUs, size.MaxChildren, size.StartServers, size.MinSpareServers, size.MaxSpareServers)
+
+	path, err := phpstart.WriteFpmSizingConfig(configPath, size, tmpDir)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(output, "PHP_START_FPM_CONFIG = %q\n", path)
+	return err
+}
+
+func memoryEnv(name, fallback string) (int64, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		value = fallback
+	}
+
+	memory, err := phpstart.ParseMemory(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse $%s value %s: must be a memory size such as 64M", name, value)
+	}
+
+	return memory, nil
+}
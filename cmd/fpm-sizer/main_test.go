@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testFpmSizer(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cgroupRoot  string
+		meminfoPath string
+		configPath  string
+		tmpDir      string
+		output      *bytes.Buffer
+		logs        *bytes.Buffer
+	)
+
+	it.Before(func() {
+		cgroupRoot = t.TempDir()
+		tmpDir = t.TempDir()
+		meminfoPath = filepath.Join(t.TempDir(), "meminfo")
+		configPath = filepath.Join(t.TempDir(), "base.conf")
+		output = bytes.NewBuffer(nil)
+		logs = bytes.NewBuffer(nil)
+
+		Expect(os.WriteFile(meminfoPath, []byte("MemTotal: 16384000 kB\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cgroupRoot, "memory.max"), []byte("536870912\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cgroupRoot, "cpu.max"), []byte("100000 100000\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(configPath, []byte("[www]\npm = static\n"), 0644)).To(Succeed())
+
+		t.Setenv("PHP_START_FPM_CONFIG", configPath)
+	})
+
+	it("points $PHP_START_FPM_CONFIG at a configuration sized for the container", func() {
+		Expect(run(output, logs, cgroupRoot, meminfoPath, tmpDir)).To(Succeed())
+
+		path := filepath.Join(tmpDir, "php-fpm-sizing.conf")
+		Expect(output.String()).To(Equal("PHP_START_FPM_CONFIG = \"" + path + "\"\n"))
+		Expect(logs.String()).To(ContainSubstring("Sizing FPM pools for 512 MiB of memory and 1.00 CPUs: pm.max_children = 7"))
+
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring("pm.max_children = 7\n"))
+	})
+
+	context("when the worker memory is configured", func() {
+		it.Before(func() {
+			t.Setenv("PHP_FPM_WORKER_MEMORY", "32M")
+			t.Setenv("PHP_FPM_RESERVED_MEMORY", "0")
+		})
+
+		it("sizes the pools for it", func() {
+			Expect(run(output, logs, cgroupRoot, meminfoPath, tmpDir)).To(Succeed())
+			Expect(logs.String()).To(ContainSubstring("pm.max_children = 16"))
+		})
+	})
+
+	context("when additional FPM pools have workers", func() {
+		it.Before(func() {
+			t.Setenv("PHP_START_FPM_POOL_WORKERS", "3")
+		})
+
+		it("keeps their memory out of the budget", func() {
+			Expect(run(output, logs, cgroupRoot, meminfoPath, tmpDir)).To(Succeed())
+			Expect(logs.String()).To(ContainSubstring("Reserving memory for 3 workers of additional FPM pools"))
+			Expect(logs.String()).To(ContainSubstring("pm.max_children = 4"))
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the workers of additional FPM pools cannot be parsed", func() {
+			it.Before(func() {
+				t.Setenv("PHP_START_FPM_POOL_WORKERS", "some")
+			})
+
+			it("returns an error", func() {
+				err := run(output, logs, cgroupRoot, meminfoPath, tmpDir)
+				Expect(err).To(MatchError("failed to parse $PHP_START_FPM_POOL_WORKERS value some: must be a number of workers"))
+			})
+		})
+
+		context("when the worker memory cannot be parsed", func() {
+			it.Before(func() {
+				t.Setenv("PHP_FPM_WORKER_MEMORY", "lots")
+			})
+
+			it("returns an error", func() {
+				Expect(run(output, logs, cgroupRoot, meminfoPath, tmpDir)).To(MatchError(ContainSubstring("failed to parse $PHP_FPM_WORKER_MEMORY value lots")))
+			})
+		})
+
+		context("when $PHP_START_FPM_CONFIG is not set", func() {
+			it.Before(func() {
+				Expect(os.Unsetenv("PHP_START_FPM_CONFIG")).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				Expect(run(output, logs, cgroupRoot, meminfoPath, tmpDir)).To(MatchError("failed to lookup $PHP_START_FPM_CONFIG"))
+			})
+		})
+	})
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitPortBinder(t *testing.T) {
+	suite := spec.New("cmd/port-binder", spec.Report(report.Terminal{}))
+	suite("Port Binder", testPortBinder)
+	suite.Run(t)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+)
+
+// The port-binder runs as an exec.d binary when the container starts. When
+// the platform assigns a $PORT, it writes a copy of the server configuration
+// whose primary $PHP_START_LISTEN_DIRECTIVE listens on that port to the
+// temporary directory, and points $PHP_START_SERVER_CONFIG at the copy by
+// writing it to file descriptor 3.
+func main() {
+	if err := run(os.NewFile(3, "/dev/fd/3"), os.TempDir()); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to bind $PORT:", err)
+		os.Exit(1)
+	}
+}
+
+func run(output io.Writer, tmpDir string) error {
+	value, ok := os.LookupEnv("PORT")
+	if !ok || value == "" {
+		return nil
+	}
+
+	port, err := phpstart.ParsePort(value)
+	if err != nil {
+		return err
+	}
+
+	binding := phpstart.PortBinding{
+		Listen:  os.Getenv(phpstart.ListenDirectiveEnv),
+		Include: os.Getenv(phpstart.IncludeDirectiveEnv),
+	}
+	if binding.Listen == "" {
+		return fmt.Errorf("failed to lookup $%s", phpstart.ListenDirectiveEnv)
+	}
+
+	configPath := os.Getenv("PHP_START_SERVER_CONFIG")
+	if configPath == "" {
+		return fmt.Errorf("failed to lookup $PHP_START_SERVER_CONFIG")
+	}
+
+	path, err := phpstart.WritePortConfig(configPath, binding, port, tmpDir)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(output, "PHP_START_SERVER_CONFIG = %q\n", path)
+	return err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testPortBinder(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		configDir string
+		tmpDir    string
+		output    *bytes.Buffer
+	)
+
+	it.Before(func() {
+		configDir = t.TempDir()
+		tmpDir = t.TempDir()
+		output = bytes.NewBuffer(nil)
+
+		Expect(os.WriteFile(filepath.Join(configDir, "httpd.conf"), []byte("Listen 8080\n"), 0644)).To(Succeed())
+
+		t.Setenv("PHP_START_LISTEN_DIRECTIVE", "Listen")
+		t.Setenv("PHP_START_SERVER_CONFIG", filepath.Join(configDir, "httpd.conf"))
+	})
+
+	context("when $PORT is set", func() {
+		it.Before(func() {
+			t.Setenv("PORT", "3000")
+		})
+
+		it("points $PHP_START_SERVER_CONFIG at a configuration that listens on it", func() {
+			Expect(run(output, tmpDir)).To(Succeed())
+
+			path := filepath.Join(tmpDir, "port-3000-httpd.conf")
+			Expect(output.String()).To(Equal("PHP_START_SERVER_CONFIG = \"" + path + "\"\n"))
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("Listen 3000\n"))
+		})
+
+		context("failure cases", func() {
+			context("when $PORT is not a port", func() {
+				it.Before(func() {
+					t.Setenv("PORT", "http")
+				})
+
+				it("returns an error", func() {
+					Expect(run(output, tmpDir)).To(MatchError(ContainSubstring(`invalid port "http"`)))
+				})
+			})
+
+			context("when $PHP_START_LISTEN_DIRECTIVE is not set", func() {
+				it.Before(func() {
+					t.Setenv("PHP_START_LISTEN_DIRECTIVE", "")
+				})
+
+				it("returns an error", func() {
+					Expect(run(output, tmpDir)).To(MatchError("failed to lookup $PHP_START_LISTEN_DIRECTIVE"))
+				})
+			})
+		})
+	})
+
+	context("when $PORT is not set", func() {
+		it.Before(func() {
+			Expect(os.Unsetenv("PORT")).To(Succeed())
+		})
+
+		it("keeps the configuration from the build", func() {
+			Expect(run(output, tmpDir)).To(Succeed())
+			Expect(output.String()).To(BeEmpty())
+		})
+	})
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+)
+
+// StatusFileEnv overrides the path of the file that the process manager
+// records its processes in, for the healthcheck subcommand to read.
+const StatusFileEnv = "PHP_START_STATUS_FILE"
+
+// status is the content of the status file.
+type status struct {
+	PID       int            `json:"pid"`
+	Processes map[string]int `json:"processes"`
+}
+
+func statusPath() string {
+	if path := os.Getenv(StatusFileEnv); path != "" {
+		return path
+	}
+	return filepath.Join(os.TempDir(), "php-start-procmgr.json")
+}
+
+// writeStatus records the process manager and the processes that it runs.
+// The file is replaced rather than rewritten, so that a health check never
+// reads half of it.
+func writeStatus(cmds map[string]*exec.Cmd) error {
+	current := status{PID: os.Getpid(), Processes: map[string]int{}}
+	for procName, cmd := range cmds {
+		current.Processes[procName] = cmd.Process.Pid
+	}
+
+	content, err := json.Marshal(current)
+	if err != nil {
+		//untested
+		return err
+	}
+
+	path := statusPath()
+	err = os.WriteFile(path+".tmp", content, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+func readStatus() (status, error) {
+	content, err := os.ReadFile(statusPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return status{}, errors.New("process manager is not running")
+	} else if err != nil {
+		return status{}, fmt.Errorf("failed to read status file: %w", err)
+	}
+
+	var current status
+	err = json.Unmarshal(content, &current)
+	if err != nil {
+		return status{}, fmt.Errorf("failed to parse status file: %w", err)
+	}
+
+	return current, nil
+}
+
+// healthCheckTimeout is the time that each health check is given to respond.
+var healthCheckTimeout = 5 * time.Second
+
+// defaultPort is the port that health checks use when $PORT is not set, which
+// is the port that the web servers of the PHP buildpacks listen on.
+const defaultPort = "8080"
+
+// healthCheck returns an error when the process manager is not running, when
+// a process that is not restarted on exit is not running, or when a process
+// fails its health check. Processes that are restarted on exit, such as
+// workers, may be down between two runs and only need to pass their health
+// check.
+func healthCheck(procs phpstart.Procs, output io.Writer) error {
+	current, err := readStatus()
+	if err != nil {
+		return err
+	}
+
+	if !processAlive(current.PID) {
+		return fmt.Errorf("process manager %d is not running", current.PID)
+	}
+
+	var procNames []string
+	for procName := range procs.Processes {
+		procNames = append(procNames, procName)
+	}
+	slices.Sort(procNames)
+
+	unhealthy := 0
+	for _, procName := range procNames {
+		proc := procs.Processes[procName]
+
+		err := checkProc(proc, current.Processes[procName])
+		if err != nil {
+			fmt.Fprintln(output, procName+":", err)
+			unhealthy++
+			continue
+		}
+		fmt.Fprintln(output, procName+": ok")
+	}
+
+	if unhealthy > 0 {
+		return fmt.Errorf("%d of %d processes are unhealthy", unhealthy, len(procNames))
+	}
+
+	return nil
+}
+
+func checkProc(proc phpstart.Proc, pid int) error {
+	// Init tasks exit once they are done
+	if proc.Init {
+		return nil
+	}
+
+	if !proc.Restart && !processAlive(pid) {
+		return errors.New("not running")
+	}
+
+	if proc.HealthCheck == nil {
+		return nil
+	}
+
+	return runHealthCheck(*proc.HealthCheck)
+}
+
+// runHealthCheck probes a process the way its health check describes.
+func runHealthCheck(check phpstart.HealthCheck) error {
+	switch {
+	case check.URL != "":
+		return checkURL(expandHealthCheck(check.URL))
+	case check.Socket != "" && check.Ping != "":
+		return checkFastCGI(expandHealthCheck(check.Socket), expandHealthCheck(check.Ping))
+	case check.Socket != "":
+		conn, err := dialSocket(expandHealthCheck(check.Socket))
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	return nil
+}
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	// Signal 0 only checks that the process exists. EPERM means that it
+	// exists but belongs to another user.
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+func expandHealthCheck(value string) string {
+	return os.Expand(value, func(name string) string {
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+
+		if name == "PORT" {
+			return defaultPort
+		}
+		return "${" + name + "}"
+	})
+}
+
+func checkURL(url string) error {
+	client := http.Client{Timeout: healthCheckTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded with status %s", url, resp.Status)
+	}
+
+	return nil
+}
+
+// dialSocket connects to a TCP address, or to a unix socket when the address
+// is a path.
+func dialSocket(address string) (net.Conn, error) {
+	network := "tcp"
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	}
+
+	conn, err := net.DialTimeout(network, address, healthCheckTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn, conn.SetDeadline(time.Now().Add(healthCheckTimeout))
+}
+
+const (
+	fcgiVersion      = 1
+	fcgiBeginRequest = 1
+	fcgiEndRequest   = 3
+	fcgiParams       = 4
+	fcgiStdin        = 5
+	fcgiStdout       = 6
+	fcgiResponder    = 1
+	fcgiRequestID    = 1
+)
+
+// checkFastCGI sends a GET request for the given path to a FastCGI server,
+// the way that cgi-fcgi does, which the run image does not include.
+func checkFastCGI(address, path string) error {
+	conn, err := dialSocket(address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var params []byte
+	for _, param := range [][2]string{
+		{"GATEWAY_INTERFACE", "CGI/1.1"},
+		{"SERVER_PROTOCOL", "HTTP/1.1"},
+		{"REQUEST_METHOD", "GET"},
+		{"REQUEST_URI", path},
+		{"SCRIPT_NAME", path},
+		{"SCRIPT_FILENAME", path},
+		{"QUERY_STRING", ""},
+	} {
+		params = appendFastCGILength(params, len(param[0]))
+		params = appendFastCGILength(params, len(param[1]))
+		params = append(params, param[0]+param[1]...)
+	}
+
+	request := bytes.NewBuffer(nil)
+	writeFastCGIRecord(request, fcgiBeginRequest, []byte{0, fcgiResponder, 0, 0, 0, 0, 0, 0})
+	writeFastCGIRecord(request, fcgiParams, params)
+	writeFastCGIRecord(request, fcgiParams, nil)
+	writeFastCGIRecord(request, fcgiStdin, nil)
+
+	_, err = conn.Write(request.Bytes())
+	if err != nil {
+		return err
+	}
+
+	var stdout []byte
+	reader := bufio.NewReader(conn)
+	for {
+		header := make([]byte, 8)
+		_, err := io.ReadFull(reader, header)
+		if err != nil {
+			return fmt.Errorf("failed to read FastCGI response: %w", err)
+		}
+
+		content := make([]byte, int(binary.BigEndian.Uint16(header[4:6]))+int(header[6]))
+		_, err = io.ReadFull(reader, content)
+		if err != nil {
+			return fmt.Errorf("failed to read FastCGI response: %w", err)
+		}
+		content = content[:binary.BigEndian.Uint16(header[4:6])]
+
+		switch header[1] {
+		case fcgiStdout:
+			stdout = append(stdout, content...)
+		case fcgiEndRequest:
+			if len(content) < 5 || content[4] != 0 {
+				return fmt.Errorf("%s did not complete the request for %s", address, path)
+			}
+			return checkFastCGIStatus(address, path, stdout)
+		}
+	}
+}
+
+// checkFastCGIStatus returns an error when the CGI response sets a Status
+// header that is not a 2xx status. Responses without one are successful.
+func checkFastCGIStatus(address, path string, stdout []byte) error {
+	headers, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(stdout))).ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse FastCGI response: %w", err)
+	}
+
+	value := headers.Get("Status")
+	if value == "" {
+		return nil
+	}
+
+	code, err := strconv.Atoi(strings.Fields(value)[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse FastCGI response status %q", value)
+	}
+
+	if code < 200 || code > 299 {
+		return fmt.Errorf("%s responded to %s with status %s", address, path, value)
+	}
+
+	return nil
+}
+
+func writeFastCGIRecord(w *bytes.Buffer, recordType byte, content []byte) {
+	header := []byte{fcgiVersion, recordType, 0, fcgiRequestID, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(header[4:6], uint16(len(content)))
+	w.Write(header)
+	w.Write(content)
+}
+
+func appendFastCGILength(b []byte, length int) []byte {
+	if length < 128 {
+		return append(b, byte(length))
+	}
+	return binary.BigEndian.AppendUint32(b, uint32(length)|1<<31)
+}
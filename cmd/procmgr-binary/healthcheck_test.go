@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/fcgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+)
+
+func testHealthCheck(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		output *bytes.Buffer
+		web    *exec.Cmd
+		procs  phpstart.Procs
+	)
+
+	writeStatus := func(current status) {
+		content := fmt.Sprintf(`{"pid": %d, "processes": {"web": %d}}`, current.PID, current.Processes["web"])
+		Expect(os.WriteFile(statusPath(), []byte(content), 0644)).To(Succeed())
+	}
+
+	it.Before(func() {
+		t.Setenv(StatusFileEnv, filepath.Join(t.TempDir(), "status.json"))
+		output = bytes.NewBuffer(nil)
+
+		web = exec.Command("sleep", "10")
+		Expect(web.Start()).To(Succeed())
+
+		procs = phpstart.Procs{
+			Processes: map[string]phpstart.Proc{
+				"web": {Command: "sleep", Args: []string{"10"}},
+			},
+		}
+		writeStatus(status{PID: os.Getpid(), Processes: map[string]int{"web": web.Process.Pid}})
+	})
+
+	it.After(func() {
+		_ = web.Process.Kill()
+		_ = web.Wait()
+	})
+
+	it("succeeds when the process manager and its processes are running", func() {
+		Expect(healthCheck(procs, output)).To(Succeed())
+		Expect(output.String()).To(Equal("web: ok\n"))
+	})
+
+	context("when the process manager is not running", func() {
+		it.Before(func() {
+			Expect(os.Remove(statusPath())).To(Succeed())
+		})
+
+		it("returns an error", func() {
+			Expect(healthCheck(procs, output)).To(MatchError("process manager is not running"))
+		})
+	})
+
+	context("when the process manager exited without removing its status", func() {
+		it.Before(func() {
+			exited := exec.Command("true")
+			Expect(exited.Run()).To(Succeed())
+			writeStatus(status{PID: exited.Process.Pid, Processes: map[string]int{"web": web.Process.Pid}})
+		})
+
+		it("returns an error", func() {
+			Expect(healthCheck(procs, output)).To(MatchError(ContainSubstring("is not running")))
+		})
+	})
+
+	context("when a process is not running", func() {
+		it.Before(func() {
+			Expect(web.Process.Kill()).To(Succeed())
+			Expect(web.Wait()).NotTo(Succeed())
+		})
+
+		it("returns an error", func() {
+			Expect(healthCheck(procs, output)).To(MatchError("1 of 1 processes are unhealthy"))
+			Expect(output.String()).To(Equal("web: not running\n"))
+		})
+
+		context("when the process is restarted on exit", func() {
+			it.Before(func() {
+				procs.Processes["web"] = phpstart.Proc{Command: "sleep", Restart: true}
+			})
+
+			it("succeeds", func() {
+				Expect(healthCheck(procs, output)).To(Succeed())
+			})
+		})
+
+		context("when the process is an init task", func() {
+			it.Before(func() {
+				procs.Processes["web"] = phpstart.Proc{Command: "sleep", Init: true}
+			})
+
+			it("succeeds", func() {
+				Expect(healthCheck(procs, output)).To(Succeed())
+			})
+		})
+	})
+
+	context("when a process has an HTTP health check", func() {
+		var server *httptest.Server
+
+		it.Before(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/health" {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+
+			_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+			Expect(err).NotTo(HaveOccurred())
+			t.Setenv("PORT", port)
+		})
+
+		it.After(func() {
+			server.Close()
+		})
+
+		it("succeeds when the URL responds with a 2xx status", func() {
+			procs.Processes["web"] = phpstart.Proc{Command: "sleep", HealthCheck: &phpstart.HealthCheck{URL: "http://127.0.0.1:${PORT}/health"}}
+			Expect(healthCheck(procs, output)).To(Succeed())
+		})
+
+		it("returns an error when the URL responds with another status", func() {
+			procs.Processes["web"] = phpstart.Proc{Command: "sleep", HealthCheck: &phpstart.HealthCheck{URL: server.URL + "/missing"}}
+			Expect(healthCheck(procs, output)).To(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring("responded with status 503 Service Unavailable"))
+		})
+	})
+
+	context("when a process has a socket health check", func() {
+		var listener net.Listener
+
+		it.Before(func() {
+			var err error
+			listener, err = net.Listen("unix", filepath.Join(t.TempDir(), "web.sock"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it.After(func() {
+			_ = listener.Close()
+		})
+
+		it("succeeds when the socket accepts connections", func() {
+			procs.Processes["web"] = phpstart.Proc{Command: "sleep", HealthCheck: &phpstart.HealthCheck{Socket: listener.Addr().String()}}
+			Expect(healthCheck(procs, output)).To(Succeed())
+		})
+
+		it("returns an error when the socket does not accept connections", func() {
+			Expect(listener.Close()).To(Succeed())
+
+			procs.Processes["web"] = phpstart.Proc{Command: "sleep", HealthCheck: &phpstart.HealthCheck{Socket: listener.Addr().String()}}
+			Expect(healthCheck(procs, output)).To(HaveOccurred())
+		})
+	})
+
+	context("when a process has a FastCGI ping health check", func() {
+		var listener net.Listener
+
+		it.Before(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+
+			go func() {
+				_ = fcgi.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/ping" {
+						http.NotFound(w, r)
+						return
+					}
+					fmt.Fprint(w, "pong")
+				}))
+			}()
+		})
+
+		it.After(func() {
+			_ = listener.Close()
+		})
+
+		it("succeeds when the server answers the ping", func() {
+			procs.Processes["web"] = phpstart.Proc{Command: "sleep", HealthCheck: &phpstart.HealthCheck{Socket: listener.Addr().String(), Ping: "/ping"}}
+			Expect(healthCheck(procs, output)).To(Succeed())
+		})
+
+		it("returns an error when the server does not answer the ping", func() {
+			procs.Processes["web"] = phpstart.Proc{Command: "sleep", HealthCheck: &phpstart.HealthCheck{Socket: listener.Addr().String(), Ping: "/status"}}
+			Expect(healthCheck(procs, output)).To(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring("responded to /status with status 404"))
+		})
+	})
+
+	context("when the process manager runs", func() {
+		it("records its processes in the status file until it stops", func() {
+			recorded := filepath.Join(t.TempDir(), "recorded.json")
+			Expect(runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"web": {Command: "sh", Args: []string{"-c", "sleep 0.2; cat " + statusPath() + " > " + recorded}},
+				},
+			})).To(Succeed())
+
+			content, err := os.ReadFile(recorded)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(MatchRegexp(fmt.Sprintf(`^{"pid":%d,"processes":{"web":\d+}}$`, os.Getpid())))
+
+			Expect(statusPath()).NotTo(BeAnExistingFile())
+		})
+	})
+}
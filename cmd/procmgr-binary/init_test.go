@@ -10,5 +10,9 @@ import (
 func TestUnitProcmgr(t *testing.T) {
 	suite := spec.New("cmd/procmgry-binary", spec.Report(report.Terminal{}))
 	suite("Procmgr Binary", testProcmgr)
+	suite("Health Check", testHealthCheck)
+	suite("Readiness", testReadiness)
+	suite("Startup", testStartup)
+	suite("Logs", testLogs)
 	suite.Run(t)
 }
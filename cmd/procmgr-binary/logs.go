@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+)
+
+// logInterval is the time between two reads of the log files.
+var logInterval = 500 * time.Millisecond
+
+// logTailer follows the log files of the processes and writes their lines
+// prefixed with the name of the process. Like the watcher, it polls, so that
+// it does not depend on inotify.
+type logTailer struct {
+	done chan struct{}
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	out  io.Writer
+}
+
+func startLogTails(procs phpstart.Procs, out io.Writer) *logTailer {
+	t := &logTailer{done: make(chan struct{}), out: out}
+	for procName, proc := range procs.Processes {
+		for _, path := range expandArgs(proc.LogFiles) {
+			// Lines that were written before the process manager started
+			// have been seen already, or belong to a previous run
+			log := &logFile{path: path}
+			log.open(true)
+
+			t.wg.Add(1)
+			go t.follow(procName, log)
+		}
+	}
+
+	return t
+}
+
+// stop stops following the log files, after the lines that were written so
+// far are written out.
+func (t *logTailer) stop() {
+	close(t.done)
+	t.wg.Wait()
+}
+
+func (t *logTailer) follow(procName string, log *logFile) {
+	defer t.wg.Done()
+	defer log.close()
+
+	ticker := time.NewTicker(logInterval)
+	defer ticker.Stop()
+
+	for {
+		stopping := false
+		select {
+		case <-t.done:
+			stopping = true
+		case <-ticker.C:
+		}
+
+		lines := log.read()
+		if stopping {
+			lines = append(lines, log.flush()...)
+		}
+
+		for _, line := range lines {
+			t.mu.Lock()
+			fmt.Fprintf(t.out, "[%s] %s\n", procName, line)
+			t.mu.Unlock()
+		}
+
+		if stopping {
+			return
+		}
+	}
+}
+
+// logFile is a log file that is read from where the previous read stopped.
+// It is read from the start again when it is truncated, or when it is
+// replaced by a new file after being rotated.
+type logFile struct {
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+}
+
+func (l *logFile) open(atEnd bool) {
+	file, err := os.Open(l.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintln(os.Stderr, "failed to open log file:", err)
+		}
+		return
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		//untested
+		_ = file.Close()
+		return
+	}
+
+	l.file, l.info, l.offset = file, info, 0
+	if atEnd {
+		l.offset = info.Size()
+	}
+}
+
+func (l *logFile) close() {
+	if l.file != nil {
+		_ = l.file.Close()
+		l.file = nil
+	}
+}
+
+// read returns the lines that were written since the previous read.
+func (l *logFile) read() []string {
+	if l.file == nil {
+		l.open(false)
+		if l.file == nil {
+			return nil
+		}
+	}
+
+	var lines []string
+	info, err := os.Stat(l.path)
+	switch {
+	case err == nil && !os.SameFile(info, l.info):
+		// The file was rotated. The rest of the old file is read before the
+		// new one.
+		lines = l.readLines()
+		lines = append(lines, l.flush()...)
+		l.close()
+		l.open(false)
+		if l.file == nil {
+			return lines
+		}
+	case err == nil && info.Size() < l.offset:
+		// The file was truncated
+		l.offset = 0
+		l.partial = nil
+	}
+
+	return append(lines, l.readLines()...)
+}
+
+func (l *logFile) readLines() []string {
+	content := make([]byte, 32*1024)
+
+	var lines []string
+	for {
+		n, err := l.file.ReadAt(content, l.offset)
+		l.offset += int64(n)
+		l.partial = append(l.partial, content[:n]...)
+
+		for {
+			i := bytes.IndexByte(l.partial, '\n')
+			if i < 0 && len(l.partial) >= outputTailLineLength {
+				i = outputTailLineLength
+			}
+			if i < 0 {
+				break
+			}
+
+			lines = append(lines, string(l.partial[:i]))
+			l.partial = bytes.TrimPrefix(l.partial[i:], []byte("\n"))
+		}
+
+		if err != nil || n == 0 {
+			return lines
+		}
+	}
+}
+
+// flush returns the last line of the file when it does not end with a
+// newline.
+func (l *logFile) flush() []string {
+	if len(l.partial) == 0 {
+		return nil
+	}
+
+	line := string(l.partial)
+	l.partial = nil
+	return []string{line}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+)
+
+func testLogs(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect     = NewWithT(t).Expect
+		Eventually = NewWithT(t).Eventually
+
+		path   string
+		buffer *gbytes.Buffer
+		tailer *logTailer
+	)
+
+	appendLog := func(path, content string) {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.WriteString(content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(file.Close()).To(Succeed())
+	}
+
+	start := func() {
+		tailer = startLogTails(phpstart.Procs{
+			Processes: map[string]phpstart.Proc{
+				"fpm": {Command: "php-fpm", LogFiles: []string{path}},
+			},
+		}, buffer)
+	}
+
+	it.Before(func() {
+		logInterval = 10 * time.Millisecond
+		path = filepath.Join(t.TempDir(), "php-fpm.slow.log")
+		buffer = gbytes.NewBuffer()
+	})
+
+	it.After(func() {
+		if tailer != nil {
+			tailer.stop()
+		}
+		logInterval = 500 * time.Millisecond
+	})
+
+	context("when the log file exists", func() {
+		it.Before(func() {
+			appendLog(path, "previous run\n")
+			start()
+		})
+
+		it("writes the new lines prefixed with the name of the process", func() {
+			appendLog(path, "script_filename = /workspace/htdocs/index.php\n")
+			Eventually(buffer).Should(gbytes.Say(`\[fpm\] script_filename = /workspace/htdocs/index.php\n`))
+			Expect(string(buffer.Contents())).NotTo(ContainSubstring("previous run"))
+		})
+
+		it("writes a line once it is complete", func() {
+			appendLog(path, "first ")
+			appendLog(path, "half\n")
+			Eventually(buffer).Should(gbytes.Say(`\[fpm\] first half\n`))
+		})
+
+		it("follows the file when it is truncated", func() {
+			appendLog(path, "before truncation\n")
+			Eventually(buffer).Should(gbytes.Say("before truncation"))
+
+			Expect(os.WriteFile(path, []byte("after\n"), 0644)).To(Succeed())
+			Eventually(buffer).Should(gbytes.Say(`\[fpm\] after\n`))
+		})
+
+		it("follows the file when it is rotated", func() {
+			Expect(os.Rename(path, path+".1")).To(Succeed())
+			appendLog(path+".1", "written before rotation\n")
+			appendLog(path, "written after rotation\n")
+
+			Eventually(buffer).Should(gbytes.Say(`\[fpm\] written before rotation\n`))
+			Eventually(buffer).Should(gbytes.Say(`\[fpm\] written after rotation\n`))
+		})
+
+		it("writes the last line when it stops", func() {
+			appendLog(path, "without newline")
+			tailer.stop()
+			tailer = nil
+
+			Expect(buffer).To(gbytes.Say(`\[fpm\] without newline\n`))
+		})
+	})
+
+	context("when the log file does not exist yet", func() {
+		it.Before(func() {
+			start()
+		})
+
+		it("writes its lines once it is created", func() {
+			time.Sleep(50 * time.Millisecond)
+			appendLog(path, "created\n")
+			Eventually(buffer).Should(gbytes.Say(`\[fpm\] created\n`))
+		})
+	})
+
+	context("when the process manager runs a process with log files", func() {
+		it.Before(func() {
+			t.Setenv(StatusFileEnv, filepath.Join(t.TempDir(), "status.json"))
+		})
+
+		it("follows them while the process runs", func() {
+			stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+			Expect(err).NotTo(HaveOccurred())
+
+			// The tailer writes to stdout, which is replaced for the test
+			original := os.Stdout
+			os.Stdout = stdout
+			err = runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"nginx": {Command: "sh", Args: []string{"-c", "echo 'upstream timed out' >> " + path + "; sleep 0.1"}, LogFiles: []string{path}},
+				},
+			})
+			os.Stdout = original
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err := os.ReadFile(stdout.Name())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("[nginx] upstream timed out\n"))
+		})
+	})
+}
@@ -1,18 +1,47 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
 	phpstart "github.com/paketo-buildpacks/php-start"
 )
 
 func main() {
+	if len(os.Args) == 3 && os.Args[1] == "healthcheck" {
+		procs, err := phpstart.ReadProcs(os.Args[2])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error loading/parsing procs file:", err)
+			os.Exit(1)
+		}
+
+		// Container health checks treat any exit code other than 0 as
+		// unhealthy, and Docker reserves 2
+		if err := healthCheck(procs, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "unhealthy:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if len(os.Args) != 2 {
 		fmt.Fprintln(os.Stderr)
 		fmt.Fprintln(os.Stderr, "USAGE:")
 		fmt.Fprintln(os.Stderr, "    procmgr <path-to-proc-file>")
+		fmt.Fprintln(os.Stderr, "    procmgr healthcheck <path-to-proc-file>")
 		fmt.Fprintln(os.Stderr)
 		os.Exit(1)
 	}
@@ -23,8 +52,14 @@ func main() {
 		os.Exit(2)
 	}
 
+	readyFile = filepath.Join(filepath.Dir(os.Args[1]), "ready")
 	if err := runProcs(procs); err != nil {
 		fmt.Fprintln(os.Stderr, "error running procs:", err)
+
+		var timeoutErr startupTimeoutError
+		if errors.As(err, &timeoutErr) {
+			os.Exit(startupTimeoutExitCode)
+		}
 		os.Exit(2)
 	}
 }
@@ -37,30 +72,542 @@ type procMsg struct {
 
 func runProcs(procs phpstart.Procs) error {
 	msgs := make(chan procMsg)
+	cmds := map[string]*exec.Cmd{}
+
+	globalTimeout, err := phpstart.ParseStartupTimeout(os.Getenv(StartupTimeoutEnv))
+	if err != nil {
+		return fmt.Errorf("invalid $%s: %w", StartupTimeoutEnv, err)
+	}
+
+	readiness, err := startReadiness()
+	if err != nil {
+		return err
+	}
+	defer readiness.stop()
+
+	// The log files are followed before the processes start, so that the
+	// lines they write when they start are not missed
+	logs := startLogTails(procs, os.Stdout)
+	defer logs.stop()
+
+	// The recent output of the processes that have to become ready in time
+	// is kept, to be shown when they do not
+	tails := map[string]*outputTail{}
+	for procName, proc := range procs.Processes {
+		if (proc.HealthCheck != nil || proc.Init) && (proc.StartupTimeout != "" || globalTimeout > 0) {
+			tails[procName] = &outputTail{}
+		}
+	}
 
 	for procName, proc := range procs.Processes {
-		go runProc(procName, proc, msgs)
+		cmd, err := startProc(procName, proc, msgs, tails[procName])
+		if err != nil {
+			stopProcs(procs, cmds)
+			return err
+		}
+
+		cmds[procName] = cmd
+	}
+
+	updateStatus(cmds)
+	defer func() {
+		_ = os.Remove(statusPath())
+	}()
+
+	schedules, err := startSchedules(procs.Schedules)
+	if err != nil {
+		stopProcs(procs, cmds)
+		return err
+	}
+	defer schedules.stop()
+
+	startupChecks, pending, err := startStartupChecks(procs)
+	if err != nil {
+		stopProcs(procs, cmds)
+		return err
+	}
+	defer startupChecks.stop()
+
+	var startupTimedOut <-chan time.Time
+	if len(pending) == 0 {
+		readiness.markReady()
+	} else if globalTimeout > 0 {
+		timer := time.NewTimer(globalTimeout)
+		defer timer.Stop()
+		startupTimedOut = timer.C
+	}
+
+	watches := make(chan watchMsg)
+	if liveReload, _ := strconv.ParseBool(os.Getenv(phpstart.LiveReloadEnv)); liveReload {
+		watcher := startWatches(procs, watches)
+		defer watcher.stop()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				reloadProcs(procs, cmds)
+				continue
+			}
+
+			readiness.markStopping()
+			stopProcs(procs, cmds)
+			for range cmds {
+				msg := <-msgs
+				fmt.Fprintln(os.Stderr, "process", msg.ProcName, "stopped, status:", msg.Cmd.ProcessState)
+			}
+			return nil
+		case procName := <-startupChecks.ready:
+			fmt.Fprintln(os.Stderr, "process", procName, "is ready")
+			delete(pending, procName)
+			if len(pending) == 0 {
+				readiness.markReady()
+				startupTimedOut = nil
+			}
+		case timeout := <-startupChecks.timeouts:
+			if !pending[timeout.procName] {
+				continue
+			}
+
+			err := startupTimeoutError{[]string{timeout.procName}, timeout.timeout}
+			dumpOutput(err, tails)
+			stopProcs(procs, cmds)
+			return err
+		case <-startupTimedOut:
+			err := startupTimeoutError{slices.Sorted(maps.Keys(pending)), globalTimeout}
+			dumpOutput(err, tails)
+			stopProcs(procs, cmds)
+			return err
+		case watch := <-watches:
+			if len(watch.Command) > 0 {
+				fmt.Fprintln(os.Stderr, watch.Path, "changed, reloading process", watch.ProcName)
+				runReloadCommand(watch.ProcName, watch.Command)
+				continue
+			}
+
+			fmt.Fprintln(os.Stderr, watch.Path, "changed, sending", watch.Signal, "to process", watch.ProcName)
+			if err := signalProc(cmds[watch.ProcName], watch.Signal); err != nil {
+				fmt.Fprintln(os.Stderr, "failed to signal process", watch.ProcName+":", err)
+			}
+		case msg := <-msgs:
+			fmt.Fprintln(os.Stderr, "process", msg.ProcName, "exited, status:", msg.Cmd.ProcessState)
+
+			proc := procs.Processes[msg.ProcName]
+			if proc.Init && msg.Err == nil {
+				// A finished init task is not stopped with the others
+				delete(cmds, msg.ProcName)
+				updateStatus(cmds)
+
+				delete(pending, msg.ProcName)
+				if len(pending) == 0 {
+					readiness.markReady()
+					startupTimedOut = nil
+				}
+				continue
+			}
+
+			if !proc.Restart || proc.Init {
+				return msg.Err
+			}
+
+			// The processes are not ready until the process has passed its
+			// health check again. Workers without one, which exit on purpose,
+			// do not change the readiness.
+			if proc.HealthCheck != nil {
+				readiness.markNotReady()
+				pending[msg.ProcName] = true
+			}
+
+			time.Sleep(restartDelay)
+			fmt.Fprintln(os.Stderr, "restarting process", msg.ProcName)
+
+			cmd, err := startProc(msg.ProcName, proc, msgs, tails[msg.ProcName])
+			if err != nil {
+				delete(cmds, msg.ProcName)
+				stopProcs(procs, cmds)
+				return err
+			}
+			cmds[msg.ProcName] = cmd
+			updateStatus(cmds)
+
+			if proc.HealthCheck != nil {
+				timeout, _ := proc.StartupTimeoutDuration()
+				startupChecks.add(msg.ProcName, *proc.HealthCheck, timeout)
+			}
+		}
 	}
+}
 
-	msg := <-msgs
-	fmt.Fprintln(os.Stderr, "process", msg.ProcName, "exited, status:", msg.Cmd.ProcessState)
-	return msg.Err
+// updateStatus writes the status file for the healthcheck subcommand. The
+// processes keep running when it cannot be written.
+func updateStatus(cmds map[string]*exec.Cmd) {
+	if err := writeStatus(cmds); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write status file:", err)
+	}
 }
 
-func runProc(procName string, proc phpstart.Proc, msgs chan procMsg) {
-	cmd := exec.Command(proc.Command, proc.Args...)
+// restartDelay is the time to wait before restarting a process that exited,
+// so that a process that keeps failing does not spin.
+var restartDelay = time.Second
+
+func startProc(procName string, proc phpstart.Proc, msgs chan procMsg, tail *outputTail) (*exec.Cmd, error) {
+	cmd := exec.Command(proc.Command, expandArgs(proc.Args)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	if tail != nil {
+		cmd.Stdout = io.MultiWriter(os.Stdout, tail)
+		cmd.Stderr = io.MultiWriter(os.Stderr, tail)
+	}
 
-	err := cmd.Start()
-	if err != nil {
-		msgs <- procMsg{procName, cmd, err}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start process %s: %w", procName, err)
+	}
+
+	go runProc(procName, cmd, msgs)
+	return cmd, nil
+}
+
+func runProc(procName string, cmd *exec.Cmd, msgs chan procMsg) {
+	err := cmd.Wait()
+	msgs <- procMsg{procName, cmd, err}
+}
+
+// reloadProcs sends each process its reload signal, or runs its reload
+// command, so that a SIGHUP sent to the process manager gracefully reloads
+// its processes.
+func reloadProcs(procs phpstart.Procs, cmds map[string]*exec.Cmd) {
+	for procName, proc := range procs.Processes {
+		if proc.ReloadSignal != "" {
+			if err := signalProc(cmds[procName], proc.ReloadSignal); err != nil {
+				fmt.Fprintln(os.Stderr, "failed to reload process", procName+":", err)
+			}
+		}
+
+		if len(proc.ReloadCommand) > 0 {
+			runReloadCommand(procName, proc.ReloadCommand)
+		}
+	}
+}
+
+// runReloadCommand runs the command that reloads the named process, and
+// waits for it to exit.
+func runReloadCommand(procName string, command []string) {
+	reloadCommand := expandArgs(command)
+	cmd := exec.Command(reloadCommand[0], reloadCommand[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to reload process", procName+":", err)
+	}
+}
+
+// stopProcs sends each running process its stop signal, which defaults to
+// SIGTERM, so that it can shut down gracefully.
+func stopProcs(procs phpstart.Procs, cmds map[string]*exec.Cmd) {
+	for procName, cmd := range cmds {
+		stopSignal := procs.Processes[procName].StopSignal
+		if stopSignal == "" {
+			stopSignal = "SIGTERM"
+		}
+
+		if err := signalProc(cmd, stopSignal); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to stop process", procName+":", err)
+		}
+	}
+}
+
+// now returns the current time, which the schedules are evaluated at.
+var now = time.Now
+
+// scheduler runs the schedules of the process file as short-lived children,
+// and logs every run and its exit status.
+type scheduler struct {
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type scheduledRun struct {
+	name     string
+	schedule phpstart.Schedule
+	cron     phpstart.CronSchedule
+	location *time.Location
+	timeout  time.Duration
+}
+
+func startSchedules(schedules map[string]phpstart.Schedule) (*scheduler, error) {
+	var runs []scheduledRun
+	for name, schedule := range schedules {
+		cron, err := schedule.CronSchedule()
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %s: %w", name, err)
+		}
+
+		location, err := schedule.Location()
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %s: %w", name, err)
+		}
+
+		timeout, err := schedule.TimeoutDuration()
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %s: %w", name, err)
+		}
+
+		runs = append(runs, scheduledRun{name, schedule, cron, location, timeout})
+	}
+
+	s := &scheduler{done: make(chan struct{})}
+	for _, run := range runs {
+		s.wg.Add(1)
+		go s.loop(run)
+	}
+
+	return s, nil
+}
+
+// stop stops scheduling new runs, sends SIGTERM to the runs that are still
+// running and waits for them to exit.
+func (s *scheduler) stop() {
+	close(s.done)
+	s.wg.Wait()
+}
+
+func (s *scheduler) loop(run scheduledRun) {
+	defer s.wg.Done()
+
+	// Holds a token while a run is running, so that overlapping runs can be
+	// skipped
+	running := make(chan struct{}, 1)
+
+	for {
+		next := run.cron.Next(now().In(run.location))
+		if next.IsZero() {
+			fmt.Fprintln(os.Stderr, "schedule", run.name, "never matches, it will not run")
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(now()))
+		select {
+		case <-s.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !run.schedule.AllowOverlap {
+			select {
+			case running <- struct{}{}:
+			default:
+				fmt.Fprintln(os.Stderr, "schedule", run.name, "skipped, previous run is still running")
+				continue
+			}
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.run(run)
+
+			if !run.schedule.AllowOverlap {
+				<-running
+			}
+		}()
+	}
+}
+
+func (s *scheduler) run(run scheduledRun) {
+	cmd := exec.Command(run.schedule.Command, expandArgs(run.schedule.Args)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	fmt.Fprintln(os.Stderr, "schedule", run.name, "started")
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintln(os.Stderr, "schedule", run.name, "failed to start:", err)
+		return
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	var timedOut <-chan time.Time
+	if run.timeout > 0 {
+		timer := time.NewTimer(run.timeout)
+		defer timer.Stop()
+		timedOut = timer.C
+	}
+
+	select {
+	case <-exited:
+	case <-timedOut:
+		fmt.Fprintln(os.Stderr, "schedule", run.name, "timed out after", run.timeout)
+		_ = cmd.Process.Kill()
+		<-exited
+	case <-s.done:
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+		<-exited
+	}
+
+	fmt.Fprintln(os.Stderr, "schedule", run.name, "exited after", time.Since(started).Round(time.Millisecond), "status:", cmd.ProcessState)
+}
+
+// watchInterval is the time between two scans of the watch paths.
+var watchInterval = time.Second
+
+type watchMsg struct {
+	ProcName string
+	Path     string
+	Signal   string
+	Command  []string
+}
+
+// watcher polls the watch paths of the processes, so that the process
+// manager does not depend on inotify, which is not available on every
+// platform that runs containers.
+type watcher struct {
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func startWatches(procs phpstart.Procs, watches chan watchMsg) *watcher {
+	w := &watcher{done: make(chan struct{})}
+	for procName, proc := range procs.Processes {
+		for _, path := range proc.Watch {
+			w.wg.Add(1)
+			go w.poll(procName, path, watches)
+		}
+	}
+
+	return w
+}
+
+func (w *watcher) stop() {
+	close(w.done)
+	w.wg.Wait()
+}
+
+func (w *watcher) poll(procName string, path phpstart.WatchPath, watches chan watchMsg) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	previous := snapshot(path)
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := snapshot(path)
+		if maps.Equal(previous, current) {
+			continue
+		}
+		previous = current
+
+		select {
+		case watches <- watchMsg{procName, path.Path, path.Signal, path.Command}:
+		case <-w.done:
+			return
+		}
 	}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot returns the state of the files at and under the watch path that
+// match its globs, or an empty snapshot when it does not exist.
+func snapshot(watch phpstart.WatchPath) map[string]fileState {
+	files := map[string]fileState{}
+	_ = filepath.WalkDir(watch.Path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
 
-	err = cmd.Wait()
+		rel, err := filepath.Rel(watch.Path, path)
+		if err != nil {
+			//untested
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel != "." && matchesAny(watch.Ignore, rel) {
+			// Ignored directories are not walked, such as vendor/**
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// With globs, only the files count, since adding an ignored file
+		// changes the directory it is added to
+		if (len(watch.Include) > 0 || len(watch.Ignore) > 0) && entry.IsDir() {
+			return nil
+		}
+
+		if len(watch.Include) > 0 && !matchesAny(watch.Include, rel) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		files[path] = fileState{info.ModTime(), info.Size()}
+		return nil
+	})
+
+	return files
+}
+
+func matchesAny(globs []string, path string) bool {
+	for _, glob := range globs {
+		if match, _ := doublestar.Match(glob, path); match {
+			return true
+		}
+	}
+
+	return false
+}
+
+func signalProc(cmd *exec.Cmd, name string) error {
+	sig, err := phpstart.ParseSignal(name)
 	if err != nil {
-		msgs <- procMsg{procName, cmd, err}
+		return err
+	}
+
+	if cmd == nil || cmd.Process == nil {
+		return nil
 	}
 
-	msgs <- procMsg{procName, cmd, nil}
+	return cmd.Process.Signal(sig)
+}
+
+// expandArgs replaces ${VAR} and $VAR references in process arguments with
+// values from the launch environment, such as the $PORT that a platform
+// assigns. References to unset variables are left untouched.
+func expandArgs(args []string) []string {
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = os.Expand(arg, func(name string) string {
+			if value, ok := os.LookupEnv(name); ok {
+				return value
+			}
+			return "${" + name + "}"
+		})
+	}
+	return expanded
 }
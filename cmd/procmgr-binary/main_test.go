@@ -1,7 +1,13 @@
 package main
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	phpstart "github.com/paketo-buildpacks/php-start"
@@ -72,6 +78,188 @@ func testProcmgr(t *testing.T, context spec.G, it spec.S) {
 		})
 	})
 
+	context("given a process that restarts", func() {
+		var output string
+
+		it.Before(func() {
+			restartDelay = 10 * time.Millisecond
+			output = filepath.Join(t.TempDir(), "output")
+		})
+
+		it.After(func() {
+			restartDelay = time.Second
+		})
+
+		it("starts it again when it exits", func() {
+			err := runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"worker": {
+						Command: "sh",
+						Args:    []string{"-c", "echo run >> " + output},
+						Restart: true,
+					},
+					"server": {
+						Command: "sh",
+						Args:    []string{"-c", "sleep 0.5; exit 1"},
+					},
+				},
+			})
+			Expect(err).To(HaveOccurred())
+
+			content, err := os.ReadFile(output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(string(content), "run")).To(BeNumerically(">", 1))
+		})
+	})
+
+	context("given a process that watches a path", func() {
+		var (
+			output   string
+			watchDir string
+			procs    phpstart.Procs
+		)
+
+		it.Before(func() {
+			watchInterval = 10 * time.Millisecond
+			output = filepath.Join(t.TempDir(), "output")
+			watchDir = t.TempDir()
+
+			procs = phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"server": {
+						Command: "sh",
+						Args: []string{"-c", strings.Join([]string{
+							"trap 'echo reloaded > " + output + "; exit 0' USR2",
+							"sleep 0.2",
+							"echo changed > " + filepath.Join(watchDir, "php.ini"),
+							"for i in 1 2 3 4 5 6 7 8 9 10; do sleep 0.1; done",
+						}, "; ")},
+						Watch: []phpstart.WatchPath{{Path: watchDir, Signal: "SIGUSR2"}},
+					},
+				},
+			}
+		})
+
+		it.After(func() {
+			watchInterval = time.Second
+		})
+
+		context("when live reload is enabled", func() {
+			it.Before(func() {
+				t.Setenv("PHP_START_LIVE_RELOAD", "true")
+			})
+
+			it("sends the process its signal when the path changes", func() {
+				Expect(runProcs(procs)).To(Succeed())
+
+				content, err := os.ReadFile(output)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("reloaded\n"))
+			})
+		})
+
+		it("does not watch the path", func() {
+			Expect(runProcs(procs)).To(Succeed())
+			Expect(output).NotTo(BeAnExistingFile())
+		})
+	})
+
+	context("given a process that runs a command when a path changes", func() {
+		var (
+			output   string
+			watchDir string
+		)
+
+		it.Before(func() {
+			watchInterval = 10 * time.Millisecond
+			output = filepath.Join(t.TempDir(), "output")
+			watchDir = t.TempDir()
+			t.Setenv("PHP_START_LIVE_RELOAD", "true")
+		})
+
+		it.After(func() {
+			watchInterval = time.Second
+		})
+
+		it("runs the command when the path changes", func() {
+			err := runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"server": {
+						Command: "sh",
+						Args: []string{"-c", strings.Join([]string{
+							"sleep 0.2",
+							"echo changed > " + filepath.Join(watchDir, "Caddyfile"),
+							"sleep 0.5",
+						}, "; ")},
+						Watch: []phpstart.WatchPath{{
+							Path:    watchDir,
+							Command: []string{"sh", "-c", "echo reloaded >> " + output},
+						}},
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err := os.ReadFile(output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("reloaded\n"))
+		})
+
+		it("only takes the files that match the globs into account", func() {
+			Expect(os.MkdirAll(filepath.Join(watchDir, "vendor"), os.ModePerm)).To(Succeed())
+
+			err := runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"fpm": {
+						Command: "sh",
+						Args: []string{"-c", strings.Join([]string{
+							"sleep 0.2",
+							"echo changed > " + filepath.Join(watchDir, "vendor", "autoload.php"),
+							"echo changed > " + filepath.Join(watchDir, "README.md"),
+							"sleep 0.2",
+							"test ! -e " + output + " || exit 1",
+							"echo changed > " + filepath.Join(watchDir, "index.php"),
+							"sleep 0.5",
+						}, "; ")},
+						Watch: []phpstart.WatchPath{{
+							Path:    watchDir,
+							Command: []string{"sh", "-c", "echo reloaded >> " + output},
+							Include: []string{"**/*.php"},
+							Ignore:  []string{"vendor/**"},
+						}},
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err := os.ReadFile(output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("reloaded\n"))
+		})
+	})
+
+	context("given a process with environment variables in its args", func() {
+		it.Before(func() {
+			t.Setenv("PROCMGR_TEST_PORT", "8080")
+		})
+
+		it("expands them before running it", func() {
+			err := runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"proc1": {
+						Command: "test",
+						Args:    []string{"0.0.0.0:${PROCMGR_TEST_PORT}", "=", "0.0.0.0:8080"},
+					},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		it("leaves unset variables untouched", func() {
+			Expect(expandArgs([]string{"$PROCMGR_TEST_PORT", "${PROCMGR_UNSET_VAR}"})).To(Equal([]string{"8080", "${PROCMGR_UNSET_VAR}"}))
+		})
+	})
+
 	context("given two processes where one is shorter", func() {
 		it("should succeed in running both", func() {
 			err := runProcs(phpstart.Procs{
@@ -89,4 +277,149 @@ func testProcmgr(t *testing.T, context spec.G, it spec.S) {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+
+	context("startSchedules", func() {
+		var output string
+
+		it.Before(func() {
+			output = filepath.Join(t.TempDir(), "output")
+
+			// A minute is about to start, so that every schedule that runs
+			// every minute is due shortly
+			now = func() time.Time {
+				return time.Date(2024, 1, 1, 0, 0, 59, 950000000, time.UTC)
+			}
+		})
+
+		it.After(func() {
+			now = time.Now
+		})
+
+		it("runs each schedule when it is due", func() {
+			s, err := startSchedules(map[string]phpstart.Schedule{
+				"cleanup": {
+					Cron:    "* * * * *",
+					Command: "sh",
+					Args:    []string{"-c", "echo run >> " + output},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			time.Sleep(300 * time.Millisecond)
+			s.stop()
+
+			content, err := os.ReadFile(output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(string(content), "run")).To(BeNumerically(">", 1))
+		})
+
+		it("skips runs that would overlap the previous run", func() {
+			s, err := startSchedules(map[string]phpstart.Schedule{
+				"cleanup": {
+					Cron:    "* * * * *",
+					Command: "sh",
+					Args:    []string{"-c", "echo run >> " + output + "; sleep 1"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			time.Sleep(300 * time.Millisecond)
+			s.stop()
+
+			content, err := os.ReadFile(output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(string(content), "run")).To(Equal(1))
+		})
+
+		it("kills runs that exceed their timeout", func() {
+			s, err := startSchedules(map[string]phpstart.Schedule{
+				"cleanup": {
+					Cron:    "* * * * *",
+					Command: "sh",
+					Args:    []string{"-c", "sleep 0.2; echo run >> " + output},
+					Timeout: "50ms",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			time.Sleep(400 * time.Millisecond)
+			s.stop()
+
+			Expect(output).NotTo(BeAnExistingFile())
+		})
+
+		context("when a schedule is invalid", func() {
+			it("returns an error", func() {
+				_, err := startSchedules(map[string]phpstart.Schedule{
+					"cleanup": {
+						Cron:    "every minute",
+						Command: "true",
+					},
+				})
+				Expect(err).To(MatchError(ContainSubstring("invalid schedule cleanup")))
+			})
+		})
+	})
+
+	context("reloadProcs", func() {
+		it("runs the reload command of each process that has one", func() {
+			reloaded := filepath.Join(t.TempDir(), "reloaded")
+
+			reloadProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"proc1": {
+						Command:       "sleep",
+						Args:          []string{"1"},
+						ReloadCommand: []string{"touch", reloaded},
+					},
+					"proc2": {
+						Command: "sleep",
+						Args:    []string{"1"},
+					},
+				},
+			}, map[string]*exec.Cmd{})
+
+			_, err := os.Stat(reloaded)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		it("sends the reload signal to each process that has one", func() {
+			cmd := exec.Command("sleep", "10")
+			Expect(cmd.Start()).To(Succeed())
+
+			reloadProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"proc1": {
+						Command:      "sleep",
+						Args:         []string{"10"},
+						ReloadSignal: "SIGUSR1",
+					},
+				},
+			}, map[string]*exec.Cmd{"proc1": cmd})
+
+			Expect(cmd.Wait()).To(HaveOccurred())
+			Expect(cmd.ProcessState.Sys().(syscall.WaitStatus).Signal()).To(Equal(syscall.SIGUSR1))
+		})
+	})
+
+	context("stopProcs", func() {
+		it("sends each process its stop signal", func() {
+			quit := exec.Command("sleep", "10")
+			term := exec.Command("sleep", "10")
+			Expect(quit.Start()).To(Succeed())
+			Expect(term.Start()).To(Succeed())
+
+			stopProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"quit": {Command: "sleep", Args: []string{"10"}, StopSignal: "SIGQUIT"},
+					"term": {Command: "sleep", Args: []string{"10"}},
+				},
+			}, map[string]*exec.Cmd{"quit": quit, "term": term})
+
+			Expect(quit.Wait()).To(HaveOccurred())
+			Expect(quit.ProcessState.Sys().(syscall.WaitStatus).Signal()).To(Equal(syscall.SIGQUIT))
+			Expect(term.Wait()).To(HaveOccurred())
+			Expect(term.ProcessState.Sys().(syscall.WaitStatus).Signal()).To(Equal(syscall.SIGTERM))
+		})
+	})
 }
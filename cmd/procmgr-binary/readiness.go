@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+)
+
+const (
+	// ReadyFileEnv overrides the path of the file that exists while the
+	// processes are ready, which defaults to a ready file next to the
+	// procs.yml.
+	ReadyFileEnv = "PHP_START_READY_FILE"
+
+	// ReadyPortEnv is the port of an HTTP endpoint, /ready, that responds with
+	// 200 while the processes are ready and with 503 otherwise.
+	ReadyPortEnv = "PHP_START_READY_PORT"
+)
+
+// readyFile is the default path of the ready file, set from the path of the
+// procs.yml that the process manager runs.
+var readyFile string
+
+func readyFilePath() string {
+	if path := os.Getenv(ReadyFileEnv); path != "" {
+		return path
+	}
+	return readyFile
+}
+
+// readiness publishes whether the processes are ready to serve traffic as a
+// file, an HTTP endpoint, and sd_notify messages when $NOTIFY_SOCKET is set.
+type readiness struct {
+	mu     sync.Mutex
+	ready  bool
+	file   string
+	server *http.Server
+}
+
+func startReadiness() (*readiness, error) {
+	r := &readiness{file: readyFilePath()}
+
+	// A ready file that was left behind by a previous run is stale
+	if r.file != "" {
+		if err := os.Remove(r.file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintln(os.Stderr, "failed to remove ready file:", err)
+		}
+	}
+
+	port, ok := os.LookupEnv(ReadyPortEnv)
+	if !ok || port == "" {
+		return r, nil
+	}
+
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on $%s: %w", ReadyPortEnv, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, req *http.Request) {
+		if !r.isReady() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ready")
+	})
+
+	r.server = &http.Server{Handler: mux, ReadHeaderTimeout: healthCheckTimeout}
+	go func() {
+		_ = r.server.Serve(listener)
+	}()
+
+	return r, nil
+}
+
+func (r *readiness) isReady() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ready
+}
+
+func (r *readiness) markReady() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ready {
+		return
+	}
+	r.ready = true
+
+	fmt.Fprintln(os.Stderr, "all processes are ready")
+	if r.file != "" {
+		if err := os.WriteFile(r.file, nil, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to write ready file:", err)
+		}
+	}
+	notify("READY=1")
+}
+
+// markNotReady reports the processes as not ready while one of them is
+// restarted, until it is ready again.
+func (r *readiness) markNotReady() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.ready {
+		return
+	}
+	r.ready = false
+
+	fmt.Fprintln(os.Stderr, "processes are not ready")
+	r.removeFile()
+}
+
+// markStopping reports the processes as not ready, before they are sent
+// their stop signals, so that traffic is drained from the container first.
+func (r *readiness) markStopping() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.ready {
+		return
+	}
+	r.ready = false
+
+	r.removeFile()
+	notify("STOPPING=1")
+}
+
+func (r *readiness) removeFile() {
+	if r.file == "" {
+		return
+	}
+
+	if err := os.Remove(r.file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "failed to remove ready file:", err)
+	}
+}
+
+func (r *readiness) stop() {
+	r.markStopping()
+	if r.server != nil {
+		_ = r.server.Close()
+	}
+}
+
+// notify sends a state to the service manager that listens on
+// $NOTIFY_SOCKET, the way that sd_notify does.
+func notify(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+
+	conn, err := net.Dial("unixgram", socket)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to notify $NOTIFY_SOCKET:", err)
+		return
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to notify $NOTIFY_SOCKET:", err)
+	}
+}
+
+// readinessInterval is the time between two startup checks of a process.
+var readinessInterval = time.Second
+
+// startupChecker runs the health checks of the processes until each one
+// passes once, which makes the process ready. Processes without a health
+// check are ready when they start, and init tasks once they exit with 0.
+type startupChecker struct {
+	done     chan struct{}
+	wg       sync.WaitGroup
+	ready    chan string
+	timeouts chan startupTimeout
+}
+
+func startStartupChecks(procs phpstart.Procs) (*startupChecker, map[string]bool, error) {
+	timeouts := map[string]time.Duration{}
+	for procName, proc := range procs.Processes {
+		timeout, err := proc.StartupTimeoutDuration()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid process %s: %w", procName, err)
+		}
+		timeouts[procName] = timeout
+	}
+
+	c := &startupChecker{
+		done:     make(chan struct{}),
+		ready:    make(chan string),
+		timeouts: make(chan startupTimeout),
+	}
+	pending := map[string]bool{}
+	for procName, proc := range procs.Processes {
+		if proc.Init {
+			pending[procName] = true
+			if timeouts[procName] > 0 {
+				c.wg.Add(1)
+				go c.expire(procName, timeouts[procName])
+			}
+			continue
+		}
+
+		if proc.HealthCheck == nil {
+			continue
+		}
+
+		pending[procName] = true
+		c.add(procName, *proc.HealthCheck, timeouts[procName])
+	}
+
+	return c, pending, nil
+}
+
+// add runs the health check of a process until it passes, such as after the
+// process was restarted.
+func (c *startupChecker) add(procName string, check phpstart.HealthCheck, timeout time.Duration) {
+	c.wg.Add(1)
+	go c.check(procName, check, timeout)
+}
+
+// expire reports the init task as timed out once its startup timeout has
+// passed. The report is ignored when the task has finished by then.
+func (c *startupChecker) expire(procName string, timeout time.Duration) {
+	defer c.wg.Done()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+	case <-timer.C:
+		select {
+		case c.timeouts <- startupTimeout{procName, timeout}:
+		case <-c.done:
+		}
+	}
+}
+
+func (c *startupChecker) stop() {
+	close(c.done)
+	c.wg.Wait()
+}
+
+func (c *startupChecker) check(procName string, check phpstart.HealthCheck, timeout time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(readinessInterval)
+	defer ticker.Stop()
+
+	var timedOut <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timedOut = timer.C
+	}
+
+	for runHealthCheck(check) != nil {
+		select {
+		case <-c.done:
+			return
+		case <-timedOut:
+			select {
+			case c.timeouts <- startupTimeout{procName, timeout}:
+			case <-c.done:
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+
+	select {
+	case c.ready <- procName:
+	case <-c.done:
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+)
+
+func testReadiness(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect     = NewWithT(t).Expect
+		Eventually = NewWithT(t).Eventually
+
+		file string
+	)
+
+	it.Before(func() {
+		file = filepath.Join(t.TempDir(), "ready")
+		t.Setenv(ReadyFileEnv, file)
+		t.Setenv(StatusFileEnv, filepath.Join(t.TempDir(), "status.json"))
+	})
+
+	context("when the processes have health checks", func() {
+		var (
+			healthy atomic.Bool
+			server  *httptest.Server
+			done    chan error
+		)
+
+		it.Before(func() {
+			readinessInterval = 10 * time.Millisecond
+
+			healthy.Store(false)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !healthy.Load() {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+
+			done = make(chan error)
+			go func() {
+				done <- runProcs(phpstart.Procs{
+					Processes: map[string]phpstart.Proc{
+						"web":    {Command: "sleep", Args: []string{"0.5"}, HealthCheck: &phpstart.HealthCheck{URL: server.URL}},
+						"worker": {Command: "sleep", Args: []string{"1"}},
+					},
+				})
+			}()
+		})
+
+		it.After(func() {
+			readinessInterval = time.Second
+			server.Close()
+		})
+
+		it("becomes ready after every health check passes", func() {
+			time.Sleep(100 * time.Millisecond)
+			Expect(file).NotTo(BeAnExistingFile())
+
+			healthy.Store(true)
+			Eventually(file).Should(BeAnExistingFile())
+
+			Expect(<-done).To(Succeed())
+			Expect(file).NotTo(BeAnExistingFile())
+		})
+	})
+
+	context("when the processes have no health checks", func() {
+		it("becomes ready when they start", func() {
+			readyWhileRunning := filepath.Join(t.TempDir(), "ready-while-running")
+			Expect(runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"web": {Command: "sh", Args: []string{"-c", fmt.Sprintf("sleep 0.2; test -e %s && touch %s", file, readyWhileRunning)}},
+				},
+			})).To(Succeed())
+
+			Expect(readyWhileRunning).To(BeAnExistingFile())
+			Expect(file).NotTo(BeAnExistingFile())
+		})
+	})
+
+	context("when a process restarts", func() {
+		var (
+			healthy atomic.Bool
+			server  *httptest.Server
+			done    chan error
+		)
+
+		it.Before(func() {
+			readinessInterval = 10 * time.Millisecond
+			restartDelay = 100 * time.Millisecond
+
+			healthy.Store(true)
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !healthy.Load() {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+
+			done = make(chan error)
+			go func() {
+				done <- runProcs(phpstart.Procs{
+					Processes: map[string]phpstart.Proc{
+						"web":     {Command: "sleep", Args: []string{"0.3"}, Restart: true, HealthCheck: &phpstart.HealthCheck{URL: server.URL}},
+						"stopper": {Command: "sleep", Args: []string{"2"}},
+					},
+				})
+			}()
+		})
+
+		it.After(func() {
+			readinessInterval = time.Second
+			restartDelay = time.Second
+			server.Close()
+		})
+
+		it("is not ready until the process passes its health check again", func() {
+			Eventually(file).Should(BeAnExistingFile())
+
+			healthy.Store(false)
+			Eventually(file).ShouldNot(BeAnExistingFile())
+
+			healthy.Store(true)
+			Eventually(file).Should(BeAnExistingFile())
+
+			Expect(<-done).To(Succeed())
+		})
+	})
+
+	context("when the processes have an init task", func() {
+		it("becomes ready once the init task has finished", func() {
+			readyDuringInit := filepath.Join(t.TempDir(), "ready-during-init")
+			readyAfterInit := filepath.Join(t.TempDir(), "ready-after-init")
+			Expect(runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"init": {Command: "sh", Args: []string{"-c", fmt.Sprintf("sleep 0.2; test -e %s && touch %s; exit 0", file, readyDuringInit)}, Init: true},
+					"web":  {Command: "sh", Args: []string{"-c", fmt.Sprintf("sleep 0.5; test -e %s && touch %s", file, readyAfterInit)}},
+				},
+			})).To(Succeed())
+
+			Expect(readyDuringInit).NotTo(BeAnExistingFile())
+			Expect(readyAfterInit).To(BeAnExistingFile())
+		})
+
+		context("when the init task fails", func() {
+			it("returns an error", func() {
+				err := runProcs(phpstart.Procs{
+					Processes: map[string]phpstart.Proc{
+						"init": {Command: "sh", Args: []string{"-c", "exit 1"}, Init: true},
+						"web":  {Command: "sleep", Args: []string{"1"}},
+					},
+				})
+				Expect(err).To(MatchError("exit status 1"))
+				Expect(file).NotTo(BeAnExistingFile())
+			})
+		})
+
+		context("when the init task has a startup timeout", func() {
+			it("becomes ready when the task finishes in time", func() {
+				Expect(runProcs(phpstart.Procs{
+					Processes: map[string]phpstart.Proc{
+						"init": {Command: "true", Init: true, StartupTimeout: "100ms"},
+						"web":  {Command: "sleep", Args: []string{"0.3"}},
+					},
+				})).To(Succeed())
+			})
+
+			it("returns an error when the task does not finish in time", func() {
+				err := runProcs(phpstart.Procs{
+					Processes: map[string]phpstart.Proc{
+						"init": {Command: "sleep", Args: []string{"1"}, Init: true, StartupTimeout: "100ms"},
+						"web":  {Command: "sleep", Args: []string{"1"}},
+					},
+				})
+				Expect(err).To(MatchError("process init did not become ready within 100ms"))
+				Expect(file).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	context("when a ready file was left behind", func() {
+		it.Before(func() {
+			Expect(os.WriteFile(file, nil, 0644)).To(Succeed())
+		})
+
+		it("removes it until the processes are ready", func() {
+			r, err := startReadiness()
+			Expect(err).NotTo(HaveOccurred())
+			defer r.stop()
+
+			Expect(file).NotTo(BeAnExistingFile())
+		})
+	})
+
+	context("when $PHP_START_READY_PORT is set", func() {
+		var url string
+
+		it.Before(func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			_, port, err := net.SplitHostPort(listener.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listener.Close()).To(Succeed())
+
+			t.Setenv(ReadyPortEnv, port)
+			url = fmt.Sprintf("http://127.0.0.1:%s/ready", port)
+		})
+
+		it("serves the readiness over HTTP", func() {
+			r, err := startReadiness()
+			Expect(err).NotTo(HaveOccurred())
+			defer r.stop()
+
+			get := func() int {
+				resp, err := http.Get(url)
+				Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+				return resp.StatusCode
+			}
+
+			Expect(get()).To(Equal(http.StatusServiceUnavailable))
+
+			r.markReady()
+			Expect(get()).To(Equal(http.StatusOK))
+
+			r.markStopping()
+			Expect(get()).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		context("when the port is in use", func() {
+			it.Before(func() {
+				listener, err := net.Listen("tcp", ":0")
+				Expect(err).NotTo(HaveOccurred())
+				t.Cleanup(func() { _ = listener.Close() })
+
+				_, port, err := net.SplitHostPort(listener.Addr().String())
+				Expect(err).NotTo(HaveOccurred())
+				t.Setenv(ReadyPortEnv, port)
+			})
+
+			it("returns an error", func() {
+				_, err := startReadiness()
+				Expect(err).To(MatchError(ContainSubstring("failed to listen on $PHP_START_READY_PORT:")))
+			})
+		})
+	})
+
+	context("when $NOTIFY_SOCKET is set", func() {
+		var socket *net.UnixConn
+
+		it.Before(func() {
+			path := filepath.Join(t.TempDir(), "notify.sock")
+
+			var err error
+			socket, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(socket.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+
+			t.Setenv("NOTIFY_SOCKET", path)
+		})
+
+		it.After(func() {
+			_ = socket.Close()
+		})
+
+		it("notifies the service manager when the processes are ready and stopping", func() {
+			r, err := startReadiness()
+			Expect(err).NotTo(HaveOccurred())
+
+			read := func() string {
+				message := make([]byte, 64)
+				n, err := socket.Read(message)
+				if err != nil && err != io.EOF {
+					return err.Error()
+				}
+				return string(message[:n])
+			}
+
+			r.markReady()
+			Expect(read()).To(Equal("READY=1"))
+			Expect(file).To(BeAnExistingFile())
+
+			r.stop()
+			Expect(read()).To(Equal("STOPPING=1"))
+			Expect(file).NotTo(BeAnExistingFile())
+		})
+	})
+}
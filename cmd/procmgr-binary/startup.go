@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// StartupTimeoutEnv is the duration, such as "2m", within which every
+	// process has to pass its health check. The processes are not limited by
+	// default.
+	StartupTimeoutEnv = "PHP_START_STARTUP_TIMEOUT"
+
+	// startupTimeoutExitCode is the exit code of the process manager when a
+	// process does not become ready in time, so that it can be told apart
+	// from a process that failed.
+	startupTimeoutExitCode = 3
+)
+
+// startupTimeoutError is returned when processes did not pass their health
+// check within their startup timeout.
+type startupTimeoutError struct {
+	procNames []string
+	timeout   time.Duration
+}
+
+func (e startupTimeoutError) Error() string {
+	if len(e.procNames) == 1 {
+		return fmt.Sprintf("process %s did not become ready within %s", e.procNames[0], e.timeout)
+	}
+	return fmt.Sprintf("processes %s did not become ready within %s", strings.Join(e.procNames, ", "), e.timeout)
+}
+
+type startupTimeout struct {
+	procName string
+	timeout  time.Duration
+}
+
+// outputTailLines is the number of lines of output that are kept for each
+// process, to be shown when it does not become ready in time.
+const outputTailLines = 50
+
+// outputTailLineLength is the length after which output without a newline is
+// kept as a line of its own.
+const outputTailLineLength = 4096
+
+// outputTail keeps the last lines that a process wrote.
+type outputTail struct {
+	mu      sync.Mutex
+	lines   []string
+	partial []byte
+}
+
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.partial = append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(t.partial, '\n')
+		if i < 0 && len(t.partial) >= outputTailLineLength {
+			i = outputTailLineLength
+		}
+		if i < 0 {
+			break
+		}
+
+		t.lines = append(t.lines, string(t.partial[:i]))
+		t.partial = bytes.TrimPrefix(t.partial[i:], []byte("\n"))
+	}
+
+	// Once twice as many lines as needed are kept, the last ones are copied,
+	// so that the slice does not grow for as long as the process runs
+	if len(t.lines) > 2*outputTailLines {
+		t.lines = append([]string(nil), t.lines[len(t.lines)-outputTailLines:]...)
+	}
+
+	return len(p), nil
+}
+
+// Lines returns the last lines that the process wrote, oldest first.
+func (t *outputTail) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := t.lines
+	if len(t.partial) > 0 {
+		lines = append(lines[:len(lines):len(lines)], string(t.partial))
+	}
+
+	if len(lines) > outputTailLines {
+		lines = lines[len(lines)-outputTailLines:]
+	}
+
+	return append([]string(nil), lines...)
+}
+
+// dumpOutput writes the recent output of the processes that did not become
+// ready to stderr.
+func dumpOutput(err startupTimeoutError, tails map[string]*outputTail) {
+	for _, procName := range err.procNames {
+		tail, ok := tails[procName]
+		if !ok {
+			continue
+		}
+
+		fmt.Fprintln(os.Stderr, "recent output of process", procName+":")
+		for _, line := range tail.Lines() {
+			fmt.Fprintln(os.Stderr, "  "+line)
+		}
+	}
+}
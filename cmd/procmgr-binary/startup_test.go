@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+)
+
+func testStartup(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		missingSocket string
+	)
+
+	it.Before(func() {
+		readinessInterval = 10 * time.Millisecond
+		missingSocket = filepath.Join(t.TempDir(), "missing.sock")
+		t.Setenv(StatusFileEnv, filepath.Join(t.TempDir(), "status.json"))
+	})
+
+	it.After(func() {
+		readinessInterval = time.Second
+	})
+
+	context("when a process does not become ready within its startup timeout", func() {
+		it("stops with a startup timeout error", func() {
+			err := runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"fpm": {
+						Command:        "sh",
+						Args:           []string{"-c", "echo loading extensions; sleep 5"},
+						HealthCheck:    &phpstart.HealthCheck{Socket: missingSocket},
+						StartupTimeout: "100ms",
+					},
+				},
+			})
+			Expect(err).To(MatchError("process fpm did not become ready within 100ms"))
+			Expect(err).To(BeAssignableToTypeOf(startupTimeoutError{}))
+		})
+	})
+
+	context("when $PHP_START_STARTUP_TIMEOUT is set", func() {
+		it.Before(func() {
+			t.Setenv(StartupTimeoutEnv, "100ms")
+		})
+
+		it("stops when the processes do not all become ready in time", func() {
+			err := runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"fpm":   {Command: "sleep", Args: []string{"5"}, HealthCheck: &phpstart.HealthCheck{Socket: missingSocket}},
+					"nginx": {Command: "sleep", Args: []string{"5"}, HealthCheck: &phpstart.HealthCheck{Socket: missingSocket}},
+					"queue": {Command: "sleep", Args: []string{"5"}},
+				},
+			})
+			Expect(err).To(MatchError("processes fpm, nginx did not become ready within 100ms"))
+		})
+
+		it("keeps running the processes once they are ready", func() {
+			err := runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"web": {Command: "sleep", Args: []string{"0.3"}},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		context("when it is invalid", func() {
+			it.Before(func() {
+				t.Setenv(StartupTimeoutEnv, "soon")
+			})
+
+			it("returns an error", func() {
+				err := runProcs(phpstart.Procs{
+					Processes: map[string]phpstart.Proc{
+						"web": {Command: "sleep", Args: []string{"5"}},
+					},
+				})
+				Expect(err).To(MatchError(`invalid $PHP_START_STARTUP_TIMEOUT: invalid startup timeout "soon": must be a positive duration`))
+			})
+		})
+	})
+
+	context("when a startup timeout of a process is invalid", func() {
+		it("returns an error", func() {
+			err := runProcs(phpstart.Procs{
+				Processes: map[string]phpstart.Proc{
+					"web": {Command: "sleep", Args: []string{"5"}, StartupTimeout: "-1s"},
+				},
+			})
+			Expect(err).To(MatchError(`invalid process web: invalid startup timeout "-1s": must be a positive duration`))
+		})
+	})
+
+	context("outputTail", func() {
+		it("keeps the last lines of output", func() {
+			tail := &outputTail{}
+			for i := 1; i <= 3*outputTailLines; i++ {
+				_, err := fmt.Fprintf(tail, "line %d\n", i)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			_, err := tail.Write([]byte("partial"))
+			Expect(err).NotTo(HaveOccurred())
+
+			lines := tail.Lines()
+			Expect(lines).To(HaveLen(outputTailLines))
+			Expect(lines[0]).To(Equal(fmt.Sprintf("line %d", 2*outputTailLines+2)))
+			Expect(lines[outputTailLines-1]).To(Equal("partial"))
+		})
+
+		it("splits long output without newlines", func() {
+			tail := &outputTail{}
+			_, err := tail.Write([]byte(strings.Repeat("x", outputTailLineLength+10)))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tail.Lines()).To(Equal([]string{strings.Repeat("x", outputTailLineLength), strings.Repeat("x", 10)}))
+		})
+	})
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sclevine/spec"
+	"github.com/sclevine/spec/report"
+)
+
+func TestUnitServerTuner(t *testing.T) {
+	suite := spec.New("cmd/server-tuner", spec.Report(report.Terminal{}))
+	suite("Server Tuner", testServerTuner)
+	suite.Run(t)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+)
+
+// The server-tuner runs as an exec.d binary when the container starts. It
+// sizes the workers of the server for the CPUs that are available to the
+// container, from the workers per CPU in $PHP_START_WORKERS_PER_CPU, and
+// writes the launch environment that the start command reads them from to
+// file descriptor 3.
+func main() {
+	err := run(os.NewFile(3, "/dev/fd/3"), os.Stderr, "/sys/fs/cgroup", "/proc/meminfo")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to tune server workers:", err)
+		os.Exit(1)
+	}
+}
+
+func run(output, logs io.Writer, cgroupRoot, meminfoPath string) error {
+	value := os.Getenv(phpstart.WorkersPerCPUEnv)
+	if value == "" {
+		return nil
+	}
+
+	perCPU, err := phpstart.ParseWorkersPerCPU(value)
+	if err != nil {
+		return fmt.Errorf("failed to parse $%s: %w", phpstart.WorkersPerCPUEnv, err)
+	}
+
+	limits, err := phpstart.ReadContainerLimits(cgroupRoot, meminfoPath)
+	if err != nil {
+		return err
+	}
+
+	env := phpstart.TuneWorkers(perCPU, limits.CPUs)
+
+	var names []string
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(logs, "Tuning server workers for %.2f CPUs:", limits.CPUs)
+	for _, name := range names {
+		fmt.Fprintf(logs, " %s = %s", name, env[name])
+	}
+	fmt.Fprintln(logs)
+
+	for _, name := range names {
+		_, err = fmt.Fprintf(output, "%s = %q\n", name, env[name])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sclevine/spec"
+)
+
+func testServerTuner(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cgroupRoot  string
+		meminfoPath string
+		output      *bytes.Buffer
+		logs        *bytes.Buffer
+	)
+
+	it.Before(func() {
+		cgroupRoot = t.TempDir()
+		meminfoPath = filepath.Join(t.TempDir(), "meminfo")
+		output = bytes.NewBuffer(nil)
+		logs = bytes.NewBuffer(nil)
+
+		Expect(os.WriteFile(meminfoPath, []byte("MemTotal: 16384000 kB\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cgroupRoot, "memory.max"), []byte("max\n"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cgroupRoot, "cpu.max"), []byte("250000 100000\n"), 0644)).To(Succeed())
+
+		t.Setenv("PHP_START_WORKERS_PER_CPU", "PHP_START_HTTPD_MAX_REQUEST_WORKERS=25,PHP_START_HTTPD_SERVER_LIMIT=1")
+	})
+
+	it("sizes the workers for the CPU quota of the container", func() {
+		Expect(run(output, logs, cgroupRoot, meminfoPath)).To(Succeed())
+		Expect(output.String()).To(Equal("PHP_START_HTTPD_MAX_REQUEST_WORKERS = \"75\"\nPHP_START_HTTPD_SERVER_LIMIT = \"3\"\n"))
+		Expect(logs.String()).To(Equal("Tuning server workers for 2.50 CPUs: PHP_START_HTTPD_MAX_REQUEST_WORKERS = 75 PHP_START_HTTPD_SERVER_LIMIT = 3\n"))
+	})
+
+	context("when the server does not tune its workers", func() {
+		it.Before(func() {
+			t.Setenv("PHP_START_WORKERS_PER_CPU", "")
+		})
+
+		it("does nothing", func() {
+			Expect(run(output, logs, cgroupRoot, meminfoPath)).To(Succeed())
+			Expect(output.String()).To(BeEmpty())
+		})
+	})
+
+	context("failure cases", func() {
+		context("when the workers per CPU cannot be parsed", func() {
+			it.Before(func() {
+				t.Setenv("PHP_START_WORKERS_PER_CPU", "PHP_START_NGINX_WORKER_PROCESSES")
+			})
+
+			it("returns an error", func() {
+				Expect(run(output, logs, cgroupRoot, meminfoPath)).To(MatchError(ContainSubstring("failed to parse $PHP_START_WORKERS_PER_CPU: invalid workers per CPU")))
+			})
+		})
+
+		context("when the CPU quota cannot be parsed", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cgroupRoot, "cpu.max"), []byte("lots 100000\n"), 0644)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				Expect(run(output, logs, cgroupRoot, meminfoPath)).NotTo(Succeed())
+			})
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package phpstart
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/fs"
+)
+
+// composerJsonPath returns the location of the app's composer.json, which can
+// be overridden with the $COMPOSER env var.
+func composerJsonPath(workingDir string) string {
+	if value, found := os.LookupEnv("COMPOSER"); found {
+		return filepath.Join(workingDir, value)
+	}
+
+	return filepath.Join(workingDir, "composer.json")
+}
+
+// composerLockPath returns the location of the lock file that Composer writes
+// next to the app's composer.json, such as composer.lock.
+func composerLockPath(workingDir string) string {
+	return strings.TrimSuffix(composerJsonPath(workingDir), ".json") + ".lock"
+}
+
+// requireComposerPackages returns whether the app requires the packages that
+// Composer installs at launch, and the reason for the decision.
+func requireComposerPackages(workingDir string) (bool, string, error) {
+	jsonName := filepath.Base(composerJsonPath(workingDir))
+	if exists, err := fs.Exists(composerJsonPath(workingDir)); err != nil {
+		return false, "", err
+	} else if !exists {
+		return false, fmt.Sprintf("no %s found", jsonName), nil
+	}
+
+	skip, err := parseBoolEnv("BP_PHP_SKIP_COMPOSER_PACKAGES")
+	if err != nil {
+		return false, "", err
+	} else if skip {
+		return false, fmt.Sprintf("%s found, but $BP_PHP_SKIP_COMPOSER_PACKAGES is true", jsonName), nil
+	}
+
+	lockName := filepath.Base(composerLockPath(workingDir))
+	if exists, err := fs.Exists(composerLockPath(workingDir)); err != nil {
+		return false, "", err
+	} else if exists {
+		return true, fmt.Sprintf("%s and %s found", jsonName, lockName), nil
+	}
+
+	return true, fmt.Sprintf("%s found without %s", jsonName, lockName), nil
+}
+
+// composerRequires returns the packages listed in the "require" section of
+// the app's composer.json. A missing or empty composer.json has no
+// requirements.
+func composerRequires(workingDir string) (map[string]string, error) {
+	contents, err := os.ReadFile(composerJsonPath(workingDir))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(string(contents)) == "" {
+		return map[string]string{}, nil
+	}
+
+	var composerJson struct {
+		Require map[string]string `json:"require"`
+	}
+	err = json.Unmarshal(contents, &composerJson)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(composerJsonPath(workingDir)), err)
+	}
+
+	if composerJson.Require == nil {
+		return map[string]string{}, nil
+	}
+
+	return composerJson.Require, nil
+}
@@ -0,0 +1,62 @@
+package phpstart
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/pexec"
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+)
+
+//go:generate faux --interface ConfigChecker --output fakes/config_checker.go
+
+// ConfigChecker runs the commands that check whether the configuration of a
+// process parses, such as "nginx -t".
+type ConfigChecker interface {
+	Check(name string, check Proc) error
+}
+
+// ConfigTester is implemented by servers that can check their configuration
+// without starting.
+type ConfigTester interface {
+	ConfigTestCommand(workingDir, configPath string) Proc
+}
+
+// ExecConfigChecker runs configuration checks with the binaries that are
+// available on the $PATH at build time.
+type ExecConfigChecker struct {
+	logger scribe.Emitter
+}
+
+func NewExecConfigChecker(logger scribe.Emitter) ExecConfigChecker {
+	return ExecConfigChecker{
+		logger: logger,
+	}
+}
+
+// Check runs the given configuration check for the named process. The check
+// is skipped when its binary is not available at build time. When the check
+// fails, its output is written to the build log and an error is returned.
+func (c ExecConfigChecker) Check(name string, check Proc) error {
+	if _, err := exec.LookPath(check.Command); err != nil {
+		c.logger.Subprocess("%s: skipped, %s is not available at build time", name, check.Command)
+		return nil
+	}
+
+	buffer := bytes.NewBuffer(nil)
+	err := pexec.NewExecutable(check.Command).Execute(pexec.Execution{
+		Args:   check.Args,
+		Stdout: buffer,
+		Stderr: buffer,
+	})
+	if err != nil {
+		c.logger.Subprocess("%s: failed", name)
+		c.logger.Detail("%s", strings.TrimSpace(buffer.String()))
+		return fmt.Errorf("failed to check %s configuration with '%s %s': %w", name, check.Command, strings.Join(check.Args, " "), err)
+	}
+
+	c.logger.Subprocess("%s: ok", name)
+	return nil
+}
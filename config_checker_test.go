@@ -0,0 +1,67 @@
+package phpstart_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/scribe"
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testConfigChecker(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		binDir  string
+		buffer  *bytes.Buffer
+		checker phpstart.ExecConfigChecker
+	)
+
+	it.Before(func() {
+		binDir = t.TempDir()
+		t.Setenv("PATH", binDir)
+
+		Expect(os.WriteFile(filepath.Join(binDir, "nginx"), []byte(`#!/bin/sh
+if [ "$2" = "bad-conf-path" ]; then
+  echo "nginx: [emerg] unexpected end of file"
+  exit 1
+fi
+echo "nginx: configuration file $2 test is successful"
+`), 0755)).To(Succeed())
+
+		buffer = bytes.NewBuffer(nil)
+		checker = phpstart.NewExecConfigChecker(scribe.NewEmitter(buffer))
+	})
+
+	context("Check", func() {
+		it("runs the check and logs that it passed", func() {
+			err := checker.Check("NGINX", phpstart.NewProc("nginx", []string{"-c", "good-conf-path"}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buffer.String()).To(ContainSubstring("NGINX: ok"))
+		})
+
+		context("when the check binary is not available", func() {
+			it("skips the check", func() {
+				err := checker.Check("HTTPD", phpstart.NewProc("httpd", []string{"-t"}))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buffer.String()).To(ContainSubstring("HTTPD: skipped, httpd is not available at build time"))
+			})
+		})
+
+		context("failure cases", func() {
+			context("when the check fails", func() {
+				it("logs the output and returns an error", func() {
+					err := checker.Check("NGINX", phpstart.NewProc("nginx", []string{"-c", "bad-conf-path"}))
+					Expect(err).To(MatchError(ContainSubstring("failed to check NGINX configuration with 'nginx -c bad-conf-path'")))
+					Expect(buffer.String()).To(ContainSubstring("NGINX: failed"))
+					Expect(buffer.String()).To(ContainSubstring("nginx: [emerg] unexpected end of file"))
+				})
+			})
+		})
+	})
+}
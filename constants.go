@@ -3,9 +3,25 @@ package phpstart
 const (
 	PhpHttpdConfig = "php-httpd-config"
 	PhpNginxConfig = "php-nginx-config"
+	PhpCaddyConfig = "php-caddy-config"
 	Nginx          = "nginx"
 	Httpd          = "httpd"
+	Caddy          = "caddy"
 	Php            = "php"
 	PhpFpm         = "php-fpm"
 	Watchexec      = "watchexec"
+	FrankenPHP     = "frankenphp"
+	Rr             = "rr"
+
+	// ComposerPackages is the requirement for the packages that Composer
+	// installs from the app's composer.json.
+	ComposerPackages = "composer-packages"
+
+	// Builtin, RoadRunner and Swoole are the $BP_PHP_SERVER values that
+	// select the PHP built-in web server or a long-running application
+	// server instead of a web server and FPM. FrankenPHP is both the
+	// $BP_PHP_SERVER value and the requirement for the FrankenPHP server.
+	Builtin    = "builtin"
+	RoadRunner = "roadrunner"
+	Swoole     = "swoole"
 )
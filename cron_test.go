@@ -0,0 +1,67 @@
+package phpstart_test
+
+import (
+	"testing"
+	"time"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testCron(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		// Monday
+		start = time.Date(2024, 1, 1, 10, 17, 30, 0, time.UTC)
+	)
+
+	next := func(expr string, from time.Time) time.Time {
+		schedule, err := phpstart.ParseCron(expr)
+		Expect(err).NotTo(HaveOccurred())
+		return schedule.Next(from)
+	}
+
+	context("Next", func() {
+		it("returns the next matching minute", func() {
+			Expect(next("* * * * *", start)).To(Equal(time.Date(2024, 1, 1, 10, 18, 0, 0, time.UTC)))
+			Expect(next("*/5 * * * *", start)).To(Equal(time.Date(2024, 1, 1, 10, 20, 0, 0, time.UTC)))
+			Expect(next("15,45 * * * *", start)).To(Equal(time.Date(2024, 1, 1, 10, 45, 0, 0, time.UTC)))
+			Expect(next("0 9-17/4 * * *", start)).To(Equal(time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)))
+		})
+
+		it("supports names, macros and Sunday as 7", func() {
+			Expect(next("0 0 1 mar *", start)).To(Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+			Expect(next("30 2 * * sat,sun", start)).To(Equal(time.Date(2024, 1, 6, 2, 30, 0, 0, time.UTC)))
+			Expect(next("0 0 * * 7", start)).To(Equal(time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)))
+			Expect(next("@daily", start)).To(Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+			Expect(next("@hourly", start)).To(Equal(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)))
+		})
+
+		it("matches either the day of month or the day of week when both are restricted", func() {
+			Expect(next("0 0 15 * fri", start)).To(Equal(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)))
+		})
+
+		it("evaluates the schedule in the location of the given time", func() {
+			location, err := time.LoadLocation("Europe/Berlin")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(next("0 3 * * *", start.In(location))).To(Equal(time.Date(2024, 1, 2, 3, 0, 0, 0, location)))
+		})
+
+		it("returns the zero time when the schedule never matches", func() {
+			Expect(next("0 0 30 2 *", start)).To(BeZero())
+		})
+	})
+
+	context("failure cases", func() {
+		it("returns an error for invalid expressions", func() {
+			for _, expr := range []string{"* * * *", "60 * * * *", "* * * foo *", "*/0 * * * *", "5-1 * * * *"} {
+				_, err := phpstart.ParseCron(expr)
+				Expect(err).To(MatchError(ContainSubstring("invalid cron expression")), expr)
+			}
+		})
+	})
+}
@@ -1,9 +1,6 @@
 package phpstart
 
 import (
-	"os"
-	"path/filepath"
-
 	"github.com/paketo-buildpacks/libreload-packit"
 	"github.com/paketo-buildpacks/packit/v2"
 	"github.com/paketo-buildpacks/packit/v2/fs"
@@ -24,12 +21,16 @@ type BuildPlanMetadata struct {
 	// Build flag requests the given requirement be made available during the
 	// build phase of the buildpack lifecycle.
 	Build bool `toml:"build"`
+
+	// Reason explains why the requirement was added.
+	Reason string `toml:"reason,omitempty"`
 }
 
 // Detect will return a packit.DetectFunc that will be invoked during the
 // detect phase of the buildpack lifecycle.
 //
-// This buildpack has two requirement groups:
+// This buildpack has a requirement group for each server in the registry
+// that has a config env var. By default these are:
 // One for HTTPD in which the following are required at launch time:
 // - "php"
 // - "php-fpm"
@@ -40,13 +41,38 @@ type BuildPlanMetadata struct {
 // - "php-fpm"
 // - "nginx"
 // - "nginx-config"
+// Another for Caddy in which the following are required at launch time:
+// - "php"
+// - "php-fpm"
+// - "caddy"
+// - "php-caddy-config"
 //
-// Additionally, this buildpack will require 'composer-packages' when a composer.json is found.
+// When $BP_PHP_SERVER names a server in the registry, only the requirement
+// group for that server is offered, and detection fails when it names none.
+// When the composer.json requires a package that indicates a server, a
+// requirement group for that server is offered first. This is how the PHP
+// built-in web server ("builtin"), which only requires "php", and the
+// application servers ("roadrunner", "frankenphp" or "swoole"), which replace
+// both the web server and php-fpm, are selected.
 //
-// This buildpack will always detect.
-func Detect(reloader Reloader) packit.DetectFunc {
+// Additionally, this buildpack will require 'composer-packages' when a
+// composer.json is found, unless $BP_PHP_SKIP_COMPOSER_PACKAGES is true. The
+// requirement metadata records whether a composer.lock was found as well.
+// When Laravel queue worker, Laravel scheduler or Symfony Messenger consumer
+// processes are enabled, or the app declares schedules, "php" is also
+// required at launch time.
+//
+// This buildpack only detects PHP apps, see isPhpApp, and otherwise fails
+// with the reason.
+func Detect(reloader Reloader, servers *ServerRegistry) packit.DetectFunc {
 	return func(context packit.DetectContext) (packit.DetectResult, error) {
-		baseRequirements := []packit.BuildPlanRequirement{
+		if isPhp, err := isPhpApp(context.WorkingDir); err != nil {
+			return packit.DetectResult{}, err
+		} else if !isPhp {
+			return packit.DetectResult{}, packit.Fail.WithMessage("no PHP app found: expected a composer.json, PHP files in the web root, an htdocs directory, or BP_PHP_START_ENABLED=true")
+		}
+
+		fpmRequirements := []packit.BuildPlanRequirement{
 			{
 				Name: Php,
 				Metadata: BuildPlanMetadata{
@@ -62,10 +88,13 @@ func Detect(reloader Reloader) packit.DetectFunc {
 			},
 		}
 
+		// Live reload restarts every server with watchexec, not only the ones
+		// that use php-fpm
+		var reloadRequirements []packit.BuildPlanRequirement
 		if shouldReload, err := reloader.ShouldEnableLiveReload(); err != nil {
 			return packit.DetectResult{}, err
 		} else if shouldReload {
-			baseRequirements = append(baseRequirements, packit.BuildPlanRequirement{
+			reloadRequirements = append(reloadRequirements, packit.BuildPlanRequirement{
 				Name: Watchexec,
 				Metadata: BuildPlanMetadata{
 					Launch: true,
@@ -73,64 +102,83 @@ func Detect(reloader Reloader) packit.DetectFunc {
 			})
 		}
 
-		composerJsonPath := filepath.Join(context.WorkingDir, "composer.json")
+		var appRequirements []packit.BuildPlanRequirement
+		if required, reason, err := requireComposerPackages(context.WorkingDir); err != nil {
+			return packit.DetectResult{}, err
+		} else if required {
+			appRequirements = append(appRequirements, packit.BuildPlanRequirement{
+				Name: ComposerPackages,
+				Metadata: BuildPlanMetadata{
+					Launch: true,
+					Reason: reason,
+				},
+			})
+		}
 
-		if value, found := os.LookupEnv("COMPOSER"); found {
-			composerJsonPath = filepath.Join(context.WorkingDir, value)
+		// Background workers and schedules run PHP at launch time, even next
+		// to a server that otherwise only needs it at build time
+		backgroundWorkers, err := workers(context.WorkingDir)
+		if err != nil {
+			return packit.DetectResult{}, err
 		}
 
-		if exists, err := fs.Exists(composerJsonPath); err != nil {
+		hasSchedules, err := fs.Exists(schedulesPath(context.WorkingDir))
+		if err != nil {
 			return packit.DetectResult{}, err
-		} else if exists {
-			baseRequirements = append(baseRequirements, packit.BuildPlanRequirement{
-				Name: "composer-packages",
+		}
+
+		if len(backgroundWorkers) > 0 || hasSchedules {
+			appRequirements = append(appRequirements, packit.BuildPlanRequirement{
+				Name: Php,
 				Metadata: BuildPlanMetadata{
 					Launch: true,
 				},
 			})
 		}
 
-		httpdFpmPlan := packit.BuildPlan{
-			Requires: []packit.BuildPlanRequirement{
-				{
-					Name: Httpd,
-					Metadata: BuildPlanMetadata{
-						Launch: true,
-					},
-				},
-				{
-					Name: PhpHttpdConfig,
-					Metadata: BuildPlanMetadata{
-						Build:  true,
-						Launch: true,
-					},
-				},
-			},
+		serverPlan := func(server Server) packit.BuildPlan {
+			var requires []packit.BuildPlanRequirement
+			if server.UsesFpm() {
+				requires = append(requires, fpmRequirements...)
+			}
+			requires = append(requires, reloadRequirements...)
+			requires = append(requires, appRequirements...)
+			requires = append(requires, server.Requirements()...)
+
+			return packit.BuildPlan{Requires: requires}
 		}
 
-		nginxFpmPlan := packit.BuildPlan{
-			Requires: []packit.BuildPlanRequirement{
-				{
-					Name: Nginx,
-					Metadata: BuildPlanMetadata{
-						Launch: true,
-					},
-				},
-				{
-					Name: PhpNginxConfig,
-					Metadata: BuildPlanMetadata{
-						Build:  true,
-						Launch: true,
-					},
-				},
-			},
+		// A server named by $BP_PHP_SERVER is the only one offered, so that
+		// the group that is resolved does not depend on the buildpack order
+		explicit, found, err := servers.explicitServer()
+		if err != nil {
+			return packit.DetectResult{}, packit.Fail.WithMessage("%s", err)
+		} else if found {
+			return packit.DetectResult{
+				Plan: serverPlan(explicit),
+			}, nil
+		}
+
+		var plans []packit.BuildPlan
+
+		selected, found, err := servers.selectedServer(context.WorkingDir)
+		if err != nil {
+			return packit.DetectResult{}, err
+		}
+
+		if found {
+			plans = append(plans, serverPlan(selected))
 		}
 
-		httpdFpmPlan.Requires = append(baseRequirements, httpdFpmPlan.Requires...)
-		nginxFpmPlan.Requires = append(baseRequirements, nginxFpmPlan.Requires...)
+		for _, server := range servers.Servers() {
+			if server.ConfigEnvVar() == "" || (found && server.Name() == selected.Name()) {
+				continue
+			}
+			plans = append(plans, serverPlan(server))
+		}
 
 		return packit.DetectResult{
-			Plan: or(httpdFpmPlan, nginxFpmPlan),
+			Plan: or(plans...),
 		}, nil
 	}
 }
@@ -26,14 +26,15 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 
 	it.Before(func() {
 		workingDir = t.TempDir()
+		Expect(os.Mkdir(filepath.Join(workingDir, "htdocs"), os.ModePerm)).To(Succeed())
 
 		reloader = &fakes.Reloader{}
 
-		detect = phpstart.Detect(reloader)
+		detect = phpstart.Detect(reloader, phpstart.DefaultServerRegistry())
 	})
 
 	context("Detect", func() {
-		it("requires either [php, php-fpm, httpd, php-httpd-config] or [php, php-fpm, nginx, php-nginx-config]", func() {
+		it("requires either [php, php-fpm, httpd, php-httpd-config], [php, php-fpm, nginx, php-nginx-config] or [php, php-fpm, caddy, php-caddy-config]", func() {
 			result, err := detect(packit.DetectContext{
 				WorkingDir: workingDir,
 			})
@@ -99,10 +100,291 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 							},
 						},
 					},
+					{
+						Requires: []packit.BuildPlanRequirement{
+							{
+								Name: phpstart.Php,
+								Metadata: phpstart.BuildPlanMetadata{
+									Build: true,
+								},
+							},
+							{
+								Name: phpstart.PhpFpm,
+								Metadata: phpstart.BuildPlanMetadata{
+									Build:  true,
+									Launch: true,
+								},
+							},
+							{
+								Name: phpstart.Caddy,
+								Metadata: phpstart.BuildPlanMetadata{
+									Launch: true,
+								},
+							},
+							{
+								Name: phpstart.PhpCaddyConfig,
+								Metadata: phpstart.BuildPlanMetadata{
+									Launch: true,
+									Build:  true,
+								},
+							},
+						},
+					},
 				},
 			}))
 		})
 
+		context("when the app does not look like a PHP app", func() {
+			it.Before(func() {
+				Expect(os.Remove(filepath.Join(workingDir, "htdocs"))).To(Succeed())
+			})
+
+			it("fails detection with the reason", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(packit.Fail.WithMessage("no PHP app found: expected a composer.json, PHP files in the web root, an htdocs directory, or BP_PHP_START_ENABLED=true")))
+			})
+
+			context("when the app has a composer.json named by $COMPOSER", func() {
+				it.Before(func() {
+					t.Setenv("COMPOSER", "app.json")
+					Expect(os.WriteFile(filepath.Join(workingDir, "app.json"), []byte("{}"), os.ModePerm)).To(Succeed())
+				})
+
+				it("detects", func() {
+					_, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			context("when the web root has PHP files", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_WEB_DIR", "public")
+					Expect(os.MkdirAll(filepath.Join(workingDir, "public", "admin"), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(workingDir, "public", "admin", "index.php"), []byte("<?php"), os.ModePerm)).To(Succeed())
+				})
+
+				it("detects", func() {
+					_, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			context("when BP_PHP_START_ENABLED is true", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_START_ENABLED", "true")
+				})
+
+				it("detects", func() {
+					_, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			context("when BP_PHP_START_ENABLED cannot be parsed", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_START_ENABLED", "sometimes")
+				})
+
+				it("returns an error", func() {
+					_, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).To(MatchError(ContainSubstring("failed to parse BP_PHP_START_ENABLED value sometimes")))
+				})
+			})
+		}, spec.Sequential())
+
+		context("when BP_PHP_SERVER is set to builtin", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_SERVER", "builtin")
+			})
+
+			it("offers only a plan that requires php", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Plan.Requires).To(Equal([]packit.BuildPlanRequirement{
+					{
+						Name: phpstart.Php,
+						Metadata: phpstart.BuildPlanMetadata{
+							Build:  true,
+							Launch: true,
+						},
+					},
+				}))
+				Expect(result.Plan.Or).To(BeEmpty())
+			})
+		}, spec.Sequential())
+
+		context("when BP_PHP_SERVER is set to nginx", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_SERVER", "nginx")
+			})
+
+			it("offers only the Nginx plan", func() {
+				result, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(result.Plan.Requires).To(ContainElement(packit.BuildPlanRequirement{
+					Name: phpstart.Nginx,
+					Metadata: phpstart.BuildPlanMetadata{
+						Launch: true,
+					},
+				}))
+				Expect(result.Plan.Or).To(BeEmpty())
+			})
+		}, spec.Sequential())
+
+		context("when BP_PHP_SERVER is set to an unsupported server", func() {
+			it.Before(func() {
+				t.Setenv("BP_PHP_SERVER", "lighttpd")
+			})
+
+			it("fails detection", func() {
+				_, err := detect(packit.DetectContext{
+					WorkingDir: workingDir,
+				})
+				Expect(err).To(MatchError(packit.Fail.WithMessage(`unsupported BP_PHP_SERVER value "lighttpd", must be one of: httpd, nginx, caddy, builtin, swoole, roadrunner, frankenphp`)))
+			})
+		}, spec.Sequential())
+
+		context("application servers", func() {
+			context("when BP_PHP_SERVER is set to frankenphp", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_SERVER", "frankenphp")
+				})
+
+				it("offers only a plan for the app server", func() {
+					result, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(result.Plan.Requires).To(Equal([]packit.BuildPlanRequirement{
+						{
+							Name: phpstart.FrankenPHP,
+							Metadata: phpstart.BuildPlanMetadata{
+								Launch: true,
+							},
+						},
+					}))
+					Expect(result.Plan.Or).To(BeEmpty())
+				})
+			})
+
+			context("when the composer.json requires spiral/roadrunner-http", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`{"require": {"spiral/roadrunner-http": "^3.0"}}`), os.ModePerm)).To(Succeed())
+				})
+
+				it("offers a plan for RoadRunner first", func() {
+					result, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(result.Plan.Requires).To(Equal([]packit.BuildPlanRequirement{
+						{
+							Name: "composer-packages",
+							Metadata: phpstart.BuildPlanMetadata{
+								Launch: true,
+								Reason: "composer.json found without composer.lock",
+							},
+						},
+						{
+							Name: phpstart.Php,
+							Metadata: phpstart.BuildPlanMetadata{
+								Build:  true,
+								Launch: true,
+							},
+						},
+						{
+							Name: phpstart.Rr,
+							Metadata: phpstart.BuildPlanMetadata{
+								Launch: true,
+							},
+						},
+					}))
+					Expect(result.Plan.Or).To(HaveLen(3))
+				})
+			})
+
+			context("when the composer.json requires laravel/octane without a Swoole package", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`{"require": {"laravel/octane": "^2.0"}}`), os.ModePerm)).To(Succeed())
+				})
+
+				it("offers the web server plans only", func() {
+					result, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(result.Plan.Requires).To(ContainElement(packit.BuildPlanRequirement{
+						Name: phpstart.PhpFpm,
+						Metadata: phpstart.BuildPlanMetadata{
+							Build:  true,
+							Launch: true,
+						},
+					}))
+					Expect(result.Plan.Or).To(HaveLen(2))
+				})
+			})
+
+			context("when a Laravel queue worker is enabled", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_LARAVEL_QUEUE", "true")
+					Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`{"require": {"laravel/framework": "^11.0"}}`), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(workingDir, "artisan"), []byte{}, os.ModePerm)).To(Succeed())
+				})
+
+				it("requires php at launch", func() {
+					result, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(result.Plan.Requires).To(ContainElement(packit.BuildPlanRequirement{
+						Name: phpstart.Php,
+						Metadata: phpstart.BuildPlanMetadata{
+							Launch: true,
+						},
+					}))
+					Expect(result.Plan.Or[0].Requires).To(ContainElement(packit.BuildPlanRequirement{
+						Name: phpstart.Php,
+						Metadata: phpstart.BuildPlanMetadata{
+							Launch: true,
+						},
+					}))
+				})
+			})
+
+			context("when the composer.json cannot be parsed", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`%%%`), os.ModePerm)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).To(MatchError(ContainSubstring("failed to parse composer.json")))
+				})
+			})
+		}, spec.Sequential())
+
 		context("composer", func() {
 			context("with composer.json", func() {
 				it.Before(func() {
@@ -119,6 +401,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 						Name: "composer-packages",
 						Metadata: phpstart.BuildPlanMetadata{
 							Launch: true,
+							Reason: "composer.json found without composer.lock",
 						},
 					}))
 
@@ -126,9 +409,61 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 						Name: "composer-packages",
 						Metadata: phpstart.BuildPlanMetadata{
 							Launch: true,
+							Reason: "composer.json found without composer.lock",
 						},
 					}))
 				})
+
+				context("with composer.lock", func() {
+					it.Before(func() {
+						Expect(os.WriteFile(filepath.Join(workingDir, "composer.lock"), []byte("{}"), os.ModePerm)).To(Succeed())
+					})
+
+					it("records it in the requirement metadata", func() {
+						result, err := detect(packit.DetectContext{
+							WorkingDir: workingDir,
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(result.Plan.Requires).To(ContainElements(packit.BuildPlanRequirement{
+							Name: "composer-packages",
+							Metadata: phpstart.BuildPlanMetadata{
+								Launch: true,
+								Reason: "composer.json and composer.lock found",
+							},
+						}))
+					})
+				})
+
+				context("when BP_PHP_SKIP_COMPOSER_PACKAGES is true", func() {
+					it.Before(func() {
+						t.Setenv("BP_PHP_SKIP_COMPOSER_PACKAGES", "true")
+					})
+
+					it("does not require composer-packages", func() {
+						result, err := detect(packit.DetectContext{
+							WorkingDir: workingDir,
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(result.Plan.Requires).ToNot(ContainElements(MatchFields(IgnoreExtras, Fields{
+							"Name": Equal("composer-packages"),
+						})))
+					})
+				})
+
+				context("when BP_PHP_SKIP_COMPOSER_PACKAGES cannot be parsed", func() {
+					it.Before(func() {
+						t.Setenv("BP_PHP_SKIP_COMPOSER_PACKAGES", "sometimes")
+					})
+
+					it("returns an error", func() {
+						_, err := detect(packit.DetectContext{
+							WorkingDir: workingDir,
+						})
+						Expect(err).To(MatchError(ContainSubstring("failed to parse BP_PHP_SKIP_COMPOSER_PACKAGES value sometimes")))
+					})
+				})
 			})
 
 			context("with $COMPOSER", func() {
@@ -152,6 +487,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 							Name: "composer-packages",
 							Metadata: phpstart.BuildPlanMetadata{
 								Launch: true,
+								Reason: "other-file.json found without other-file.lock",
 							},
 						}))
 
@@ -159,6 +495,7 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 							Name: "composer-packages",
 							Metadata: phpstart.BuildPlanMetadata{
 								Launch: true,
+								Reason: "other-file.json found without other-file.lock",
 							},
 						}))
 					})
@@ -207,6 +544,35 @@ func testDetect(t *testing.T, context spec.G, it spec.S) {
 				}))
 			})
 
+			context("when BP_PHP_SERVER is set to a server without php-fpm", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_SERVER", "builtin")
+				})
+
+				it("will require watchexec", func() {
+					result, err := detect(packit.DetectContext{
+						WorkingDir: workingDir,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(result.Plan.Requires).To(Equal([]packit.BuildPlanRequirement{
+						{
+							Name: "watchexec",
+							Metadata: phpstart.BuildPlanMetadata{
+								Launch: true,
+							},
+						},
+						{
+							Name: phpstart.Php,
+							Metadata: phpstart.BuildPlanMetadata{
+								Build:  true,
+								Launch: true,
+							},
+						},
+					}))
+				})
+			})
+
 			context("failure cases", func() {
 				context("when reloader returns an error", func() {
 					it.Before(func() {
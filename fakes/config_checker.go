@@ -0,0 +1,34 @@
+package fakes
+
+import (
+	"sync"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+)
+
+type ConfigChecker struct {
+	CheckCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Name  string
+			Check phpstart.Proc
+		}
+		Returns struct {
+			Error error
+		}
+		Stub func(string, phpstart.Proc) error
+	}
+}
+
+func (f *ConfigChecker) Check(param1 string, param2 phpstart.Proc) error {
+	f.CheckCall.mutex.Lock()
+	defer f.CheckCall.mutex.Unlock()
+	f.CheckCall.CallCount++
+	f.CheckCall.Receives.Name = param1
+	f.CheckCall.Receives.Check = param2
+	if f.CheckCall.Stub != nil {
+		return f.CheckCall.Stub(param1, param2)
+	}
+	return f.CheckCall.Returns.Error
+}
@@ -16,6 +16,15 @@ type ProcMgr struct {
 		}
 		Stub func(string, phpstart.Proc)
 	}
+	AddScheduleCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Name     string
+			Schedule phpstart.Schedule
+		}
+		Stub func(string, phpstart.Schedule)
+	}
 	WriteFileCall struct {
 		mutex     sync.Mutex
 		CallCount int
@@ -39,6 +48,16 @@ func (f *ProcMgr) Add(param1 string, param2 phpstart.Proc) {
 		f.AddCall.Stub(param1, param2)
 	}
 }
+func (f *ProcMgr) AddSchedule(param1 string, param2 phpstart.Schedule) {
+	f.AddScheduleCall.mutex.Lock()
+	defer f.AddScheduleCall.mutex.Unlock()
+	f.AddScheduleCall.CallCount++
+	f.AddScheduleCall.Receives.Name = param1
+	f.AddScheduleCall.Receives.Schedule = param2
+	if f.AddScheduleCall.Stub != nil {
+		f.AddScheduleCall.Stub(param1, param2)
+	}
+}
 func (f *ProcMgr) WriteFile(param1 string) error {
 	f.WriteFileCall.mutex.Lock()
 	defer f.WriteFileCall.mutex.Unlock()
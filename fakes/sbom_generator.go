@@ -0,0 +1,35 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+type SBOMGenerator struct {
+	GenerateCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			Path    string
+			Formats []string
+		}
+		Returns struct {
+			SBOMFormatter packit.SBOMFormatter
+			Error         error
+		}
+		Stub func(string, []string) (packit.SBOMFormatter, error)
+	}
+}
+
+func (f *SBOMGenerator) Generate(param1 string, param2 []string) (packit.SBOMFormatter, error) {
+	f.GenerateCall.mutex.Lock()
+	defer f.GenerateCall.mutex.Unlock()
+	f.GenerateCall.CallCount++
+	f.GenerateCall.Receives.Path = param1
+	f.GenerateCall.Receives.Formats = param2
+	if f.GenerateCall.Stub != nil {
+		return f.GenerateCall.Stub(param1, param2)
+	}
+	return f.GenerateCall.Returns.SBOMFormatter, f.GenerateCall.Returns.Error
+}
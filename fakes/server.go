@@ -0,0 +1,172 @@
+package fakes
+
+import (
+	"sync"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	phpstart "github.com/paketo-buildpacks/php-start"
+)
+
+type Server struct {
+	ConfigEnvVarCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			String string
+		}
+		Stub func() string
+	}
+	DisplayNameCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			String string
+		}
+		Stub func() string
+	}
+	NameCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			String string
+		}
+		Stub func() string
+	}
+	ReloadDirCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			String string
+		}
+		Stub func() string
+	}
+	ReloadSignalCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			String string
+		}
+		Stub func() string
+	}
+	RequirementsCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			BuildPlanRequirementSlice []packit.BuildPlanRequirement
+		}
+		Stub func() []packit.BuildPlanRequirement
+	}
+	StartCommandCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Receives  struct {
+			WorkingDir string
+			ConfigPath string
+		}
+		Returns struct {
+			Proc  phpstart.Proc
+			Error error
+		}
+		Stub func(string, string) (phpstart.Proc, error)
+	}
+	StopSignalCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			String string
+		}
+		Stub func() string
+	}
+	UsesFpmCall struct {
+		mutex     sync.Mutex
+		CallCount int
+		Returns   struct {
+			Bool bool
+		}
+		Stub func() bool
+	}
+}
+
+func (f *Server) ConfigEnvVar() string {
+	f.ConfigEnvVarCall.mutex.Lock()
+	defer f.ConfigEnvVarCall.mutex.Unlock()
+	f.ConfigEnvVarCall.CallCount++
+	if f.ConfigEnvVarCall.Stub != nil {
+		return f.ConfigEnvVarCall.Stub()
+	}
+	return f.ConfigEnvVarCall.Returns.String
+}
+func (f *Server) DisplayName() string {
+	f.DisplayNameCall.mutex.Lock()
+	defer f.DisplayNameCall.mutex.Unlock()
+	f.DisplayNameCall.CallCount++
+	if f.DisplayNameCall.Stub != nil {
+		return f.DisplayNameCall.Stub()
+	}
+	return f.DisplayNameCall.Returns.String
+}
+func (f *Server) Name() string {
+	f.NameCall.mutex.Lock()
+	defer f.NameCall.mutex.Unlock()
+	f.NameCall.CallCount++
+	if f.NameCall.Stub != nil {
+		return f.NameCall.Stub()
+	}
+	return f.NameCall.Returns.String
+}
+func (f *Server) ReloadDir() string {
+	f.ReloadDirCall.mutex.Lock()
+	defer f.ReloadDirCall.mutex.Unlock()
+	f.ReloadDirCall.CallCount++
+	if f.ReloadDirCall.Stub != nil {
+		return f.ReloadDirCall.Stub()
+	}
+	return f.ReloadDirCall.Returns.String
+}
+func (f *Server) ReloadSignal() string {
+	f.ReloadSignalCall.mutex.Lock()
+	defer f.ReloadSignalCall.mutex.Unlock()
+	f.ReloadSignalCall.CallCount++
+	if f.ReloadSignalCall.Stub != nil {
+		return f.ReloadSignalCall.Stub()
+	}
+	return f.ReloadSignalCall.Returns.String
+}
+func (f *Server) Requirements() []packit.BuildPlanRequirement {
+	f.RequirementsCall.mutex.Lock()
+	defer f.RequirementsCall.mutex.Unlock()
+	f.RequirementsCall.CallCount++
+	if f.RequirementsCall.Stub != nil {
+		return f.RequirementsCall.Stub()
+	}
+	return f.RequirementsCall.Returns.BuildPlanRequirementSlice
+}
+func (f *Server) StartCommand(param1 string, param2 string) (phpstart.Proc, error) {
+	f.StartCommandCall.mutex.Lock()
+	defer f.StartCommandCall.mutex.Unlock()
+	f.StartCommandCall.CallCount++
+	f.StartCommandCall.Receives.WorkingDir = param1
+	f.StartCommandCall.Receives.ConfigPath = param2
+	if f.StartCommandCall.Stub != nil {
+		return f.StartCommandCall.Stub(param1, param2)
+	}
+	return f.StartCommandCall.Returns.Proc, f.StartCommandCall.Returns.Error
+}
+func (f *Server) StopSignal() string {
+	f.StopSignalCall.mutex.Lock()
+	defer f.StopSignalCall.mutex.Unlock()
+	f.StopSignalCall.CallCount++
+	if f.StopSignalCall.Stub != nil {
+		return f.StopSignalCall.Stub()
+	}
+	return f.StopSignalCall.Returns.String
+}
+func (f *Server) UsesFpm() bool {
+	f.UsesFpmCall.mutex.Lock()
+	defer f.UsesFpmCall.mutex.Unlock()
+	f.UsesFpmCall.CallCount++
+	if f.UsesFpmCall.Stub != nil {
+		return f.UsesFpmCall.Stub()
+	}
+	return f.UsesFpmCall.Returns.Bool
+}
@@ -0,0 +1,132 @@
+package phpstart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FpmPool is an additional php-fpm master process, started next to the one
+// configured through $PHP_FPM_PATH, for example to serve admin traffic with a
+// larger memory limit than public traffic.
+type FpmPool struct {
+	Name       string
+	ConfigPath string
+}
+
+// ReadFpmPools returns the FPM configurations in the .php.fpm.pools.d
+// directory of the app in workingDir, and the ones listed, relative to the
+// app, in the comma-separated $BP_PHP_FPM_POOLS. Each pool is named after its
+// configuration file, such as "fpm-admin" for admin.conf.
+func ReadFpmPools(workingDir string) ([]FpmPool, error) {
+	paths, err := filepath.Glob(filepath.Join(workingDir, ".php.fpm.pools.d", "*.conf"))
+	if err != nil {
+		//untested
+		return nil, err
+	}
+
+	for _, path := range strings.Split(os.Getenv("BP_PHP_FPM_POOLS"), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+		paths = append(paths, path)
+	}
+
+	var pools []FpmPool
+	configPaths := map[string]string{}
+	for _, path := range paths {
+		name := "fpm-" + strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if existing, ok := configPaths[name]; ok {
+			if existing == filepath.Clean(path) {
+				continue
+			}
+			return nil, fmt.Errorf("FPM pool %s is configured by both %s and %s", name, existing, path)
+		}
+		configPaths[name] = filepath.Clean(path)
+
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("failed to find FPM pool configuration: %w", err)
+		}
+
+		pools = append(pools, FpmPool{Name: name, ConfigPath: path})
+	}
+
+	return pools, nil
+}
+
+var (
+	fpmListen      = regexp.MustCompile(`(?m)^\s*listen\s*=\s*(\S+)`)
+	fpmPingPath    = regexp.MustCompile(`(?m)^\s*ping\.path\s*=\s*(\S+)`)
+	fpmMaxChildren = regexp.MustCompile(`(?m)^\s*pm\.max_children\s*=\s*(\S+)`)
+)
+
+// ListenAddress returns the address that the first pool in the configuration
+// listens on, as a host and port or the path of a unix socket, or an empty
+// string if the configuration does not set one.
+func (p FpmPool) ListenAddress() (string, error) {
+	address, err := p.directive(fpmListen)
+	if err != nil || address == "" {
+		return "", err
+	}
+
+	if !strings.Contains(address, ":") && !strings.HasPrefix(address, "/") {
+		// A port on its own listens on all addresses
+		address = "127.0.0.1:" + address
+	}
+
+	return address, nil
+}
+
+// PingPath returns the ping.path of the first pool in the configuration, or
+// an empty string if the configuration does not set one.
+func (p FpmPool) PingPath() (string, error) {
+	return p.directive(fpmPingPath)
+}
+
+// MaxChildren returns the sum of pm.max_children of the pools in the
+// configuration, which is the most workers that the master process starts.
+func (p FpmPool) MaxChildren() (int, error) {
+	config, err := os.ReadFile(p.ConfigPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read FPM pool configuration: %w", err)
+	}
+
+	matches := fpmMaxChildren.FindAllSubmatch(config, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("FPM pool configuration %s does not set pm.max_children", p.ConfigPath)
+	}
+
+	var total int
+	for _, match := range matches {
+		value := strings.Trim(string(match[1]), `"'`)
+		children, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse pm.max_children value %q in %s: %w", value, p.ConfigPath, err)
+		}
+		total += children
+	}
+
+	return total, nil
+}
+
+func (p FpmPool) directive(pattern *regexp.Regexp) (string, error) {
+	config, err := os.ReadFile(p.ConfigPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read FPM pool configuration: %w", err)
+	}
+
+	match := pattern.FindSubmatch(config)
+	if match == nil {
+		return "", nil
+	}
+
+	return strings.Trim(string(match[1]), `"'`), nil
+}
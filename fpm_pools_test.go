@@ -0,0 +1,222 @@
+package phpstart_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testFpmPools(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+	)
+
+	it.Before(func() {
+		workingDir = t.TempDir()
+	})
+
+	context("ReadFpmPools", func() {
+		context("when the app has no pools", func() {
+			it("returns none", func() {
+				pools, err := phpstart.ReadFpmPools(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pools).To(BeEmpty())
+			})
+		})
+
+		context("when the app has a .php.fpm.pools.d directory", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, ".php.fpm.pools.d"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, ".php.fpm.pools.d", "admin.conf"), nil, 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, ".php.fpm.pools.d", "api.conf"), nil, 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, ".php.fpm.pools.d", "README.md"), nil, 0644)).To(Succeed())
+			})
+
+			it("returns a pool for each configuration", func() {
+				pools, err := phpstart.ReadFpmPools(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pools).To(Equal([]phpstart.FpmPool{
+					{Name: "fpm-admin", ConfigPath: filepath.Join(workingDir, ".php.fpm.pools.d", "admin.conf")},
+					{Name: "fpm-api", ConfigPath: filepath.Join(workingDir, ".php.fpm.pools.d", "api.conf")},
+				}))
+			})
+
+			context("when BP_PHP_FPM_POOLS lists a configuration in the directory", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_FPM_POOLS", ".php.fpm.pools.d/admin.conf")
+				})
+
+				it("returns the pool once", func() {
+					pools, err := phpstart.ReadFpmPools(workingDir)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(pools).To(HaveLen(2))
+				})
+			})
+		})
+
+		context("when BP_PHP_FPM_POOLS is set", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(workingDir, "config"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "config", "admin.conf"), nil, 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(workingDir, "config", "reports.fpm"), nil, 0644)).To(Succeed())
+				t.Setenv("BP_PHP_FPM_POOLS", "config/admin.conf, config/reports.fpm")
+			})
+
+			it("returns a pool for each listed configuration", func() {
+				pools, err := phpstart.ReadFpmPools(workingDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pools).To(Equal([]phpstart.FpmPool{
+					{Name: "fpm-admin", ConfigPath: filepath.Join(workingDir, "config", "admin.conf")},
+					{Name: "fpm-reports", ConfigPath: filepath.Join(workingDir, "config", "reports.fpm")},
+				}))
+			})
+
+			context("when two configurations have the same name", func() {
+				it.Before(func() {
+					Expect(os.MkdirAll(filepath.Join(workingDir, ".php.fpm.pools.d"), os.ModePerm)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(workingDir, ".php.fpm.pools.d", "admin.conf"), nil, 0644)).To(Succeed())
+				})
+
+				it("returns an error", func() {
+					_, err := phpstart.ReadFpmPools(workingDir)
+					Expect(err).To(MatchError(ContainSubstring("FPM pool fpm-admin is configured by both")))
+				})
+			})
+
+			context("when a listed configuration does not exist", func() {
+				it.Before(func() {
+					t.Setenv("BP_PHP_FPM_POOLS", "config/missing.conf")
+				})
+
+				it("returns an error", func() {
+					_, err := phpstart.ReadFpmPools(workingDir)
+					Expect(err).To(MatchError(ContainSubstring("failed to find FPM pool configuration:")))
+				})
+			})
+		})
+	})
+
+	context("ListenAddress", func() {
+		var pool phpstart.FpmPool
+
+		it.Before(func() {
+			pool = phpstart.FpmPool{Name: "fpm-admin", ConfigPath: filepath.Join(workingDir, "admin.conf")}
+		})
+
+		context("when the pool listens on a host and port", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(pool.ConfigPath, []byte("[global]\npid = /tmp/admin.pid\n\n[admin]\nlisten = 127.0.0.1:9001\n"), 0644)).To(Succeed())
+			})
+
+			it("returns the address", func() {
+				address, err := pool.ListenAddress()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(address).To(Equal("127.0.0.1:9001"))
+			})
+		})
+
+		context("when the pool listens on a port", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(pool.ConfigPath, []byte("[admin]\n  listen=9001\n"), 0644)).To(Succeed())
+			})
+
+			it("returns the local address", func() {
+				address, err := pool.ListenAddress()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(address).To(Equal("127.0.0.1:9001"))
+			})
+		})
+
+		context("when the pool listens on a unix socket", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(pool.ConfigPath, []byte("[admin]\n;listen = 9001\nlisten = \"/tmp/admin.sock\"\nlisten.mode = 0660\n"), 0644)).To(Succeed())
+			})
+
+			it("returns the socket path", func() {
+				address, err := pool.ListenAddress()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(address).To(Equal("/tmp/admin.sock"))
+			})
+		})
+
+		context("when the configuration does not set a listen address", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(pool.ConfigPath, []byte("[admin]\ninclude = /tmp/admin.d/*.conf\n"), 0644)).To(Succeed())
+			})
+
+			it("returns an empty address", func() {
+				address, err := pool.ListenAddress()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(address).To(BeEmpty())
+			})
+		})
+
+		context("when the configuration cannot be read", func() {
+			it("returns an error", func() {
+				_, err := pool.ListenAddress()
+				Expect(err).To(MatchError(ContainSubstring("failed to read FPM pool configuration:")))
+			})
+		})
+	})
+
+	context("PingPath", func() {
+		var pool phpstart.FpmPool
+
+		it.Before(func() {
+			pool = phpstart.FpmPool{Name: "fpm-admin", ConfigPath: filepath.Join(workingDir, "admin.conf")}
+		})
+
+		it("returns the ping path", func() {
+			Expect(os.WriteFile(pool.ConfigPath, []byte("[admin]\nping.path = /ping\nping.response = pong\n"), 0644)).To(Succeed())
+
+			path, err := pool.PingPath()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal("/ping"))
+		})
+
+		it("returns an empty path when the configuration does not set one", func() {
+			Expect(os.WriteFile(pool.ConfigPath, []byte("[admin]\nlisten = 9001\n"), 0644)).To(Succeed())
+
+			path, err := pool.PingPath()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(BeEmpty())
+		})
+	})
+
+	context("MaxChildren", func() {
+		var pool phpstart.FpmPool
+
+		it.Before(func() {
+			pool = phpstart.FpmPool{Name: "fpm-admin", ConfigPath: filepath.Join(workingDir, "admin.conf")}
+		})
+
+		it("returns the sum of pm.max_children of the pools", func() {
+			Expect(os.WriteFile(pool.ConfigPath, []byte("[admin]\npm = ondemand\npm.max_children = 2\n\n[reports]\npm = static\npm.max_children = 3\n"), 0644)).To(Succeed())
+
+			children, err := pool.MaxChildren()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(children).To(Equal(5))
+		})
+
+		it("returns an error when the configuration does not set pm.max_children", func() {
+			Expect(os.WriteFile(pool.ConfigPath, []byte("[admin]\nlisten = 9001\n"), 0644)).To(Succeed())
+
+			_, err := pool.MaxChildren()
+			Expect(err).To(MatchError(ContainSubstring("does not set pm.max_children")))
+		})
+
+		it("returns an error when pm.max_children cannot be parsed", func() {
+			Expect(os.WriteFile(pool.ConfigPath, []byte("[admin]\npm.max_children = many\n"), 0644)).To(Succeed())
+
+			_, err := pool.MaxChildren()
+			Expect(err).To(MatchError(ContainSubstring(`failed to parse pm.max_children value "many"`)))
+		})
+	})
+}
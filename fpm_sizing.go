@@ -0,0 +1,361 @@
+package phpstart
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ContainerLimits are the resources that are available to the container.
+type ContainerLimits struct {
+	// Memory is the memory limit in bytes.
+	Memory int64
+
+	// CPUs is the number of CPUs that the container may use, which can be a
+	// fraction of a CPU.
+	CPUs float64
+}
+
+// ReadContainerLimits reads the memory limit and CPU quota of the container
+// from the cgroup v2 or v1 hierarchy mounted at cgroupRoot, such as
+// /sys/fs/cgroup. When the container is not limited, the total memory from
+// meminfoPath, such as /proc/meminfo, and the number of CPUs of the host are
+// used instead.
+func ReadContainerLimits(cgroupRoot, meminfoPath string) (ContainerLimits, error) {
+	memory, err := readMemoryLimit(cgroupRoot)
+	if err != nil {
+		return ContainerLimits{}, err
+	}
+
+	if memory == 0 {
+		memory, err = readMemTotal(meminfoPath)
+		if err != nil {
+			return ContainerLimits{}, err
+		}
+	}
+
+	cpus, err := readCPUQuota(cgroupRoot)
+	if err != nil {
+		return ContainerLimits{}, err
+	}
+
+	if cpus == 0 {
+		cpus = float64(runtime.NumCPU())
+	}
+
+	return ContainerLimits{Memory: memory, CPUs: cpus}, nil
+}
+
+// cgroup v1 reports a page-aligned maximum value when memory is not limited
+const unlimitedMemoryV1 = math.MaxInt64 / 4096 * 4096
+
+func readMemoryLimit(cgroupRoot string) (int64, error) {
+	for _, path := range []string{
+		filepath.Join(cgroupRoot, "memory.max"),
+		filepath.Join(cgroupRoot, "memory", "memory.limit_in_bytes"),
+	} {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return 0, fmt.Errorf("failed to read memory limit: %w", err)
+		}
+
+		value := strings.TrimSpace(string(content))
+		if value == "max" {
+			return 0, nil
+		}
+
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse memory limit %q from %s: %w", value, path, err)
+		}
+
+		if limit >= unlimitedMemoryV1 {
+			return 0, nil
+		}
+
+		return limit, nil
+	}
+
+	return 0, nil
+}
+
+func readCPUQuota(cgroupRoot string) (float64, error) {
+	content, err := os.ReadFile(filepath.Join(cgroupRoot, "cpu.max"))
+	if err == nil {
+		fields := strings.Fields(string(content))
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0, nil
+		}
+
+		return parseCPUQuota(fields[0], fields[1])
+	} else if !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to read CPU quota: %w", err)
+	}
+
+	quota, err := os.ReadFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_quota_us"))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to read CPU quota: %w", err)
+	}
+
+	period, err := os.ReadFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read CPU period: %w", err)
+	}
+
+	if strings.TrimSpace(string(quota)) == "-1" {
+		return 0, nil
+	}
+
+	return parseCPUQuota(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+func parseCPUQuota(quota, period string) (float64, error) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse CPU quota %q: %w", quota, err)
+	}
+
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, fmt.Errorf("failed to parse CPU period %q", period)
+	}
+
+	return q / p, nil
+}
+
+func readMemTotal(meminfoPath string) (int64, error) {
+	file, err := os.Open(meminfoPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read total memory: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kilobytes, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse total memory %q: %w", fields[1], err)
+			}
+			return kilobytes * 1024, nil
+		}
+	}
+
+	return 0, fmt.Errorf("failed to find MemTotal in %s", meminfoPath)
+}
+
+// ParseMemory parses a memory size in bytes, or with a K, M or G suffix,
+// such as "64M".
+func ParseMemory(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(value), "B"))
+
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1 << 30
+	}
+
+	number, err := strconv.ParseInt(strings.TrimRight(value, "KMG"), 10, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid memory size %q", value)
+	}
+
+	return number * multiplier, nil
+}
+
+// FpmPoolWorkersEnv is the launch env var through which Build passes the
+// pm.max_children of the additional FPM pools to the fpm-sizer exec.d binary,
+// which keeps the memory of those workers out of the budget that it sizes.
+const FpmPoolWorkersEnv = "PHP_START_FPM_POOL_WORKERS"
+
+// FpmPoolSize are the process manager settings of an FPM pool.
+type FpmPoolSize struct {
+	MaxChildren     int
+	StartServers    int
+	MinSpareServers int
+	MaxSpareServers int
+}
+
+// SizeFpmPool returns the pool settings for the given container limits. The
+// number of workers is the memory that remains after reservedMemory is set
+// aside for the web server and the FPM master, divided by the memory of a
+// single worker. The number of spare workers follows the number of CPUs.
+func SizeFpmPool(limits ContainerLimits, workerMemory, reservedMemory int64) FpmPoolSize {
+	maxChildren := 1
+	if workerMemory > 0 && limits.Memory > reservedMemory {
+		maxChildren = int((limits.Memory - reservedMemory) / workerMemory)
+	}
+	maxChildren = max(maxChildren, 1)
+
+	cpus := max(int(math.Ceil(limits.CPUs)), 1)
+	minSpare := min(cpus, maxChildren)
+	maxSpare := min(cpus*2, maxChildren)
+
+	return FpmPoolSize{
+		MaxChildren:     maxChildren,
+		StartServers:    minSpare + (maxSpare-minSpare)/2,
+		MinSpareServers: minSpare,
+		MaxSpareServers: maxSpare,
+	}
+}
+
+var (
+	fpmSection = regexp.MustCompile(`^\s*\[([^\]]+)\]`)
+	fpmInclude = regexp.MustCompile(`^\s*include\s*=\s*(\S+)`)
+	fpmPm      = regexp.MustCompile(`^\s*pm\s*=\s*(\S+)`)
+)
+
+// fpmPoolModes returns the pools in the FPM configuration at configPath, and
+// in the configurations that it includes, in the order in which they are
+// first defined, along with the process manager mode of each one.
+func fpmPoolModes(configPath string) ([]string, map[string]string, error) {
+	var pools []string
+	modes := map[string]string{}
+	seen := map[string]bool{}
+
+	var read func(path string) error
+	read = func(path string) error {
+		if seen[path] {
+			return nil
+		}
+		seen[path] = true
+
+		config, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read FPM configuration: %w", err)
+		}
+
+		section := "global"
+		for _, line := range strings.Split(string(config), "\n") {
+			if match := fpmSection.FindStringSubmatch(line); match != nil {
+				section = strings.TrimSpace(match[1])
+				if _, ok := modes[section]; !ok && section != "global" {
+					pools = append(pools, section)
+					modes[section] = ""
+				}
+				continue
+			}
+
+			if match := fpmInclude.FindStringSubmatch(line); match != nil {
+				// php-fpm resolves a relative include against its prefix,
+				// which is only known to the php-fpm binary itself
+				pattern := strings.Trim(match[1], `"'`)
+				if !filepath.IsAbs(pattern) {
+					return fmt.Errorf("failed to resolve FPM include %s in %s: relative includes are resolved against the php-fpm prefix, use an absolute path instead", pattern, path)
+				}
+
+				includes, err := filepath.Glob(pattern)
+				if err != nil {
+					return fmt.Errorf("failed to resolve FPM include %s: %w", pattern, err)
+				}
+
+				for _, include := range includes {
+					if err := read(include); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			if match := fpmPm.FindStringSubmatch(line); match != nil && section != "global" {
+				modes[section] = strings.Trim(match[1], `"'`)
+			}
+		}
+
+		return nil
+	}
+
+	if err := read(configPath); err != nil {
+		return nil, nil, err
+	}
+
+	return pools, modes, nil
+}
+
+// splitFpmPool splits size across count pools. The workers are divided
+// evenly, with the first pools taking the remainder, and the spare servers
+// of each pool follow its share of the workers. Every pool keeps at least one
+// worker.
+func splitFpmPool(size FpmPoolSize, count int) []FpmPoolSize {
+	sizes := make([]FpmPoolSize, count)
+	for i := range sizes {
+		maxChildren := size.MaxChildren / count
+		if i < size.MaxChildren%count {
+			maxChildren++
+		}
+		maxChildren = max(maxChildren, 1)
+
+		minSpare := min(max(size.MinSpareServers/count, 1), maxChildren)
+		maxSpare := min(max(size.MaxSpareServers/count, minSpare), maxChildren)
+
+		sizes[i] = FpmPoolSize{
+			MaxChildren:     maxChildren,
+			StartServers:    minSpare + (maxSpare-minSpare)/2,
+			MinSpareServers: minSpare,
+			MaxSpareServers: maxSpare,
+		}
+	}
+
+	return sizes
+}
+
+// WriteFpmSizingConfig writes an FPM configuration to outputDir that
+// includes the configuration at configPath, and then overrides the process
+// manager settings of each of its pools, including the pools in the
+// configurations that it includes. The workers of size are split across the
+// pools, so that together they stay within the budget. The process manager
+// mode of each pool is kept: only pm.max_children is set for static and
+// ondemand pools. It returns the path of the written configuration.
+func WriteFpmSizingConfig(configPath string, size FpmPoolSize, outputDir string) (string, error) {
+	pools, modes, err := fpmPoolModes(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	if len(pools) == 0 {
+		pools = []string{"www"}
+	}
+
+	var override strings.Builder
+	fmt.Fprintf(&override, "include=%s\n", configPath)
+	for i, poolSize := range splitFpmPool(size, len(pools)) {
+		pool := pools[i]
+
+		// Settings in a section with the name of an existing pool apply to
+		// that pool
+		fmt.Fprintf(&override, "\n[%s]\n", pool)
+		fmt.Fprintf(&override, "pm.max_children = %d\n", poolSize.MaxChildren)
+
+		switch modes[pool] {
+		case "static", "ondemand":
+			continue
+		}
+
+		fmt.Fprintf(&override, "pm.start_servers = %d\n", poolSize.StartServers)
+		fmt.Fprintf(&override, "pm.min_spare_servers = %d\n", poolSize.MinSpareServers)
+		fmt.Fprintf(&override, "pm.max_spare_servers = %d\n", poolSize.MaxSpareServers)
+	}
+
+	path := filepath.Join(outputDir, "php-fpm-sizing.conf")
+	err = os.WriteFile(path, []byte(override.String()), 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write FPM configuration: %w", err)
+	}
+
+	return path, nil
+}
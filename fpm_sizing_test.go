@@ -0,0 +1,226 @@
+package phpstart_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testFpmSizing(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		cgroupRoot  string
+		meminfoPath string
+	)
+
+	it.Before(func() {
+		cgroupRoot = t.TempDir()
+		meminfoPath = filepath.Join(t.TempDir(), "meminfo")
+		Expect(os.WriteFile(meminfoPath, []byte("MemTotal:       16384000 kB\nMemFree:         1000 kB\n"), 0644)).To(Succeed())
+	})
+
+	context("ReadContainerLimits", func() {
+		context("with cgroup v2", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cgroupRoot, "memory.max"), []byte("536870912\n"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(cgroupRoot, "cpu.max"), []byte("150000 100000\n"), 0644)).To(Succeed())
+			})
+
+			it("reads the memory limit and CPU quota", func() {
+				limits, err := phpstart.ReadContainerLimits(cgroupRoot, meminfoPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(limits).To(Equal(phpstart.ContainerLimits{Memory: 512 << 20, CPUs: 1.5}))
+			})
+
+			context("when the container is not limited", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(cgroupRoot, "memory.max"), []byte("max\n"), 0644)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(cgroupRoot, "cpu.max"), []byte("max 100000\n"), 0644)).To(Succeed())
+				})
+
+				it("uses the total memory and CPUs of the host", func() {
+					limits, err := phpstart.ReadContainerLimits(cgroupRoot, meminfoPath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(limits).To(Equal(phpstart.ContainerLimits{Memory: 16384000 * 1024, CPUs: float64(runtime.NumCPU())}))
+				})
+			})
+		})
+
+		context("with cgroup v1", func() {
+			it.Before(func() {
+				Expect(os.MkdirAll(filepath.Join(cgroupRoot, "memory"), os.ModePerm)).To(Succeed())
+				Expect(os.MkdirAll(filepath.Join(cgroupRoot, "cpu"), os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(cgroupRoot, "memory", "memory.limit_in_bytes"), []byte("1073741824\n"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_quota_us"), []byte("200000\n"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_period_us"), []byte("100000\n"), 0644)).To(Succeed())
+			})
+
+			it("reads the memory limit and CPU quota", func() {
+				limits, err := phpstart.ReadContainerLimits(cgroupRoot, meminfoPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(limits).To(Equal(phpstart.ContainerLimits{Memory: 1 << 30, CPUs: 2}))
+			})
+
+			context("when the container is not limited", func() {
+				it.Before(func() {
+					Expect(os.WriteFile(filepath.Join(cgroupRoot, "memory", "memory.limit_in_bytes"), []byte("9223372036854771712\n"), 0644)).To(Succeed())
+					Expect(os.WriteFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_quota_us"), []byte("-1\n"), 0644)).To(Succeed())
+				})
+
+				it("uses the total memory and CPUs of the host", func() {
+					limits, err := phpstart.ReadContainerLimits(cgroupRoot, meminfoPath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(limits).To(Equal(phpstart.ContainerLimits{Memory: 16384000 * 1024, CPUs: float64(runtime.NumCPU())}))
+				})
+			})
+		})
+
+		context("when the memory limit cannot be parsed", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(cgroupRoot, "memory.max"), []byte("lots\n"), 0644)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := phpstart.ReadContainerLimits(cgroupRoot, meminfoPath)
+				Expect(err).To(MatchError(ContainSubstring(`failed to parse memory limit "lots"`)))
+			})
+		})
+	})
+
+	context("ParseMemory", func() {
+		it("parses sizes with a suffix", func() {
+			Expect(phpstart.ParseMemory("1024")).To(Equal(int64(1024)))
+			Expect(phpstart.ParseMemory("64K")).To(Equal(int64(64 << 10)))
+			Expect(phpstart.ParseMemory("64M")).To(Equal(int64(64 << 20)))
+			Expect(phpstart.ParseMemory("2g")).To(Equal(int64(2 << 30)))
+			Expect(phpstart.ParseMemory("128MB")).To(Equal(int64(128 << 20)))
+		})
+
+		it("rejects invalid sizes", func() {
+			_, err := phpstart.ParseMemory("lots")
+			Expect(err).To(MatchError(ContainSubstring("invalid memory size")))
+		})
+	})
+
+	context("SizeFpmPool", func() {
+		it("sizes the pool for the memory and CPUs", func() {
+			Expect(phpstart.SizeFpmPool(phpstart.ContainerLimits{Memory: 1 << 30, CPUs: 2}, 64<<20, 64<<20)).To(Equal(phpstart.FpmPoolSize{
+				MaxChildren:     15,
+				StartServers:    3,
+				MinSpareServers: 2,
+				MaxSpareServers: 4,
+			}))
+		})
+
+		it("keeps at least one worker in small containers", func() {
+			Expect(phpstart.SizeFpmPool(phpstart.ContainerLimits{Memory: 96 << 20, CPUs: 0.5}, 64<<20, 64<<20)).To(Equal(phpstart.FpmPoolSize{
+				MaxChildren:     1,
+				StartServers:    1,
+				MinSpareServers: 1,
+				MaxSpareServers: 1,
+			}))
+		})
+	})
+
+	context("WriteFpmSizingConfig", func() {
+		var (
+			configPath string
+			outputDir  string
+		)
+
+		it.Before(func() {
+			configPath = filepath.Join(t.TempDir(), "base.conf")
+			outputDir = t.TempDir()
+			Expect(os.WriteFile(configPath, []byte("[global]\npid = run/php-fpm.pid\n\n[www]\npm = dynamic\n\n[admin]\npm = static\n\n[api]\npm = ondemand\n"), 0644)).To(Succeed())
+		})
+
+		it("includes the configuration and overrides each pool for its process manager mode", func() {
+			path, err := phpstart.WriteFpmSizingConfig(configPath, phpstart.FpmPoolSize{MaxChildren: 8, StartServers: 3, MinSpareServers: 2, MaxSpareServers: 4}, outputDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal(filepath.Join(outputDir, "php-fpm-sizing.conf")))
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(content)).To(Equal("include=" + configPath + "\n" +
+				"\n[www]\npm.max_children = 3\npm.start_servers = 1\npm.min_spare_servers = 1\npm.max_spare_servers = 1\n" +
+				"\n[admin]\npm.max_children = 3\n" +
+				"\n[api]\npm.max_children = 2\n"))
+		})
+
+		context("when the configuration has a single pool", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(configPath, []byte("[www]\npm = dynamic\n"), 0644)).To(Succeed())
+			})
+
+			it("gives the pool the whole size", func() {
+				path, err := phpstart.WriteFpmSizingConfig(configPath, phpstart.FpmPoolSize{MaxChildren: 8, StartServers: 3, MinSpareServers: 2, MaxSpareServers: 4}, outputDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(string(content)).To(Equal("include=" + configPath + "\n" +
+					"\n[www]\npm.max_children = 8\npm.start_servers = 3\npm.min_spare_servers = 2\npm.max_spare_servers = 4\n"))
+			})
+		})
+
+		context("when the configuration includes other configurations", func() {
+			it.Before(func() {
+				includeDir := filepath.Join(filepath.Dir(configPath), ".php.fpm.d")
+				Expect(os.Mkdir(includeDir, os.ModePerm)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(includeDir, "admin.conf"), []byte("[admin]\npm = static\n"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(includeDir, "www.conf"), []byte("[www]\npm = ondemand\n"), 0644)).To(Succeed())
+				Expect(os.WriteFile(configPath, []byte("[global]\ninclude="+includeDir+"/*.conf\n\n[www]\npm = dynamic\n"), 0644)).To(Succeed())
+			})
+
+			it("overrides the pools of the included configurations", func() {
+				path, err := phpstart.WriteFpmSizingConfig(configPath, phpstart.FpmPoolSize{MaxChildren: 8, StartServers: 3, MinSpareServers: 2, MaxSpareServers: 4}, outputDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+
+				// The www pool is dynamic, since its settings in the including
+				// configuration come after the include
+				Expect(string(content)).To(Equal("include=" + configPath + "\n" +
+					"\n[admin]\npm.max_children = 4\n" +
+					"\n[www]\npm.max_children = 4\npm.start_servers = 1\npm.min_spare_servers = 1\npm.max_spare_servers = 2\n"))
+			})
+		})
+
+		context("when the configuration includes a relative path", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(configPath, []byte("[global]\ninclude=etc/php-fpm.d/*.conf\n"), 0644)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := phpstart.WriteFpmSizingConfig(configPath, phpstart.FpmPoolSize{MaxChildren: 1, StartServers: 1, MinSpareServers: 1, MaxSpareServers: 1}, outputDir)
+				Expect(err).To(MatchError(ContainSubstring("failed to resolve FPM include etc/php-fpm.d/*.conf")))
+				Expect(err).To(MatchError(ContainSubstring("use an absolute path instead")))
+			})
+		})
+
+		context("when the configuration has no pools", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(configPath, []byte("[global]\ninclude=/workspace/.php.fpm.d/*.conf\n"), 0644)).To(Succeed())
+			})
+
+			it("overrides the www pool", func() {
+				path, err := phpstart.WriteFpmSizingConfig(configPath, phpstart.FpmPoolSize{MaxChildren: 1, StartServers: 1, MinSpareServers: 1, MaxSpareServers: 1}, outputDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				content, err := os.ReadFile(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("\n[www]\n"))
+			})
+		})
+	})
+}
@@ -0,0 +1,47 @@
+package phpstart
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// serverHealthCheck returns an HTTP probe of the $BP_PHP_HEALTHCHECK_PATH of
+// the web server, or nil when it is not set. The procmgr-binary expands $PORT
+// when it runs the check.
+func serverHealthCheck() (*HealthCheck, error) {
+	path, err := healthCheckPath("BP_PHP_HEALTHCHECK_PATH")
+	if err != nil || path == "" {
+		return nil, err
+	}
+
+	return &HealthCheck{URL: "http://127.0.0.1:${PORT}" + path}, nil
+}
+
+// fpmHealthCheck returns a FastCGI ping of the $BP_PHP_FPM_PING_PATH of the
+// FPM pool that listens on $BP_PHP_FPM_LISTEN. The pool has to set the same
+// ping.path. Without a ping path, the check only connects to the pool when
+// connect is true, such as when FPM has a startup timeout, and it is nil
+// otherwise.
+func fpmHealthCheck(connect bool) (*HealthCheck, error) {
+	path, err := healthCheckPath("BP_PHP_FPM_PING_PATH")
+	if err != nil || (path == "" && !connect) {
+		return nil, err
+	}
+
+	address := os.Getenv("BP_PHP_FPM_LISTEN")
+	if address == "" {
+		address = "127.0.0.1:9000"
+	}
+
+	return &HealthCheck{Socket: address, Ping: path}, nil
+}
+
+func healthCheckPath(name string) (string, error) {
+	path := os.Getenv(name)
+	if path != "" && !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("invalid %s value %q: must start with /", name, path)
+	}
+
+	return path, nil
+}
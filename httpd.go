@@ -0,0 +1,70 @@
+package phpstart
+
+import "github.com/paketo-buildpacks/packit/v2"
+
+// HttpdServer runs Apache HTTPD in front of php-fpm, using the configuration
+// provided through $PHP_HTTPD_PATH.
+type HttpdServer struct{}
+
+func NewHttpdServer() HttpdServer {
+	return HttpdServer{}
+}
+
+func (HttpdServer) Name() string {
+	return Httpd
+}
+
+func (HttpdServer) DisplayName() string {
+	return "HTTPD"
+}
+
+func (HttpdServer) Requirements() []packit.BuildPlanRequirement {
+	return []packit.BuildPlanRequirement{
+		{
+			Name: Httpd,
+			Metadata: BuildPlanMetadata{
+				Launch: true,
+			},
+		},
+		{
+			Name: PhpHttpdConfig,
+			Metadata: BuildPlanMetadata{
+				Build:  true,
+				Launch: true,
+			},
+		},
+	}
+}
+
+func (HttpdServer) ConfigEnvVar() string {
+	return "PHP_HTTPD_PATH"
+}
+
+func (HttpdServer) UsesFpm() bool {
+	return true
+}
+
+func (HttpdServer) StartCommand(workingDir, configPath string) (Proc, error) {
+	return NewProc("httpd", []string{"-f", configPath, "-k", "start", "-DFOREGROUND"}), nil
+}
+
+// ReloadSignal is SIGHUP, which makes HTTPD reload its configuration.
+// https://httpd.apache.org/docs/2.4/stopping.html
+func (HttpdServer) ReloadSignal() string {
+	return "SIGHUP"
+}
+
+func (HttpdServer) ReloadDir() string {
+	return ".httpd.conf.d"
+}
+
+// StopSignal is SIGWINCH, which makes HTTPD finish serving current requests
+// before it exits.
+func (HttpdServer) StopSignal() string {
+	return "SIGWINCH"
+}
+
+// ConfigTestCommand runs a syntax check of the HTTPD configuration.
+func (HttpdServer) ConfigTestCommand(workingDir, configPath string) Proc {
+	return NewProc("httpd", []string{"-t", "-f", configPath})
+}
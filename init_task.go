@@ -0,0 +1,24 @@
+package phpstart
+
+import (
+	"os"
+	"strings"
+)
+
+// InitTaskName is the name of the init task in the process file.
+const InitTaskName = "init"
+
+// initTask returns the one-shot task that $BP_PHP_INIT_COMMAND sets, such as
+// "php artisan migrate --force", or false when it is not set. The command runs
+// through sh next to the other processes, and they are only reported ready
+// once it exits with 0.
+func initTask() (Proc, bool) {
+	command := strings.TrimSpace(os.Getenv("BP_PHP_INIT_COMMAND"))
+	if command == "" {
+		return Proc{}, false
+	}
+
+	proc := NewProc("sh", []string{"-c", command})
+	proc.Init = true
+	return proc, true
+}
@@ -10,7 +10,15 @@ import (
 func TestUnitPhpStart(t *testing.T) {
 	suite := spec.New("php-start", spec.Report(report.Terminal{}), spec.Parallel())
 	suite("Build", testBuild, spec.Sequential())
+	suite("ConfigChecker", testConfigChecker, spec.Sequential())
+	suite("Cron", testCron)
 	suite("Detect", testDetect)
+	suite("FpmSizing", testFpmSizing)
+	suite("FpmPools", testFpmPools, spec.Sequential())
+	suite("Server", testServer)
+	suite("Port", testPort)
+	suite("WorkerTuning", testWorkerTuning)
+	suite("Schedule", testSchedule)
 	suite("TestProcmgrLib", testProcmgrLib)
 	suite.Run(t)
 }
@@ -143,13 +143,14 @@ func testHttpdReload(t *testing.T, context spec.G, it spec.S) {
 
 			Expect(logs).To(ContainLines(
 				"  Determining start commands to include in procs.yml:",
-				MatchRegexp(`    HTTPD: watchexec --watch /workspace/\.httpd\.conf\.d --on-busy-update signal --signal SIGHUP --shell none -- httpd -f /layers/.*/php-httpd-config/httpd\.conf -k start -DFOREGROUND`),
-				MatchRegexp(`    FPM: watchexec --watch /workspace/\.php\.fpm\.d --on-busy-update signal --signal SIGUSR2 --shell none -- php-fpm -y /layers/.*/php-fpm-config/base.conf -c /layers/.*/php/etc`),
+				MatchRegexp(`    HTTPD: httpd -f /layers/.*/php-httpd-config/httpd\.conf -k start -DFOREGROUND`),
+				MatchRegexp(`    FPM: php-fpm -y /layers/.*/php-fpm-config/base.conf -c /layers/.*/php/etc`),
 			))
 
 			Expect(logs).To(ContainLines(
 				"  Assigning launch processes:",
-				fmt.Sprintf("    web (default): procmgr-binary /layers/%s/php-start/procs.yml", strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
+				fmt.Sprintf("    web: procmgr-binary /layers/%s/php-start/procs.yml", strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
+				fmt.Sprintf("    reload-web (default): watchexec --restart --watch /workspace/.httpd.conf.d --watch /workspace/.php.fpm.d --shell none -- procmgr-binary /layers/%s/php-start/procs.yml", strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
 			))
 
 			container, err = docker.Container.Run.
@@ -197,13 +198,14 @@ func testHttpdReload(t *testing.T, context spec.G, it spec.S) {
 
 			Expect(logs).To(ContainLines(
 				"  Determining start commands to include in procs.yml:",
-				MatchRegexp(`    HTTPD: watchexec --watch /workspace/\.httpd\.conf\.d --on-busy-update signal --signal SIGHUP --shell none -- httpd -f /layers/.*/php-httpd-config/httpd\.conf -k start -DFOREGROUND`),
-				MatchRegexp(`    FPM: watchexec --watch /workspace/\.php\.fpm\.d --on-busy-update signal --signal SIGUSR2 --shell none -- php-fpm -y /layers/.*/php-fpm-config/base.conf -c /layers/.*/php/etc`),
+				MatchRegexp(`    HTTPD: httpd -f /layers/.*/php-httpd-config/httpd\.conf -k start -DFOREGROUND`),
+				MatchRegexp(`    FPM: php-fpm -y /layers/.*/php-fpm-config/base.conf -c /layers/.*/php/etc`),
 			))
 
 			Expect(logs).To(ContainLines(
 				"  Assigning launch processes:",
-				fmt.Sprintf("    web (default): procmgr-binary /layers/%s/php-start/procs.yml", strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
+				fmt.Sprintf("    web: procmgr-binary /layers/%s/php-start/procs.yml", strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
+				fmt.Sprintf("    reload-web (default): watchexec --restart --watch /workspace/.httpd.conf.d --watch /workspace/.php.fpm.d --shell none -- procmgr-binary /layers/%s/php-start/procs.yml", strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
 			))
 
 			container, err = docker.Container.Run.
@@ -223,8 +225,8 @@ func testHttpdReload(t *testing.T, context spec.G, it spec.S) {
 				Expect(err).NotTo(HaveOccurred())
 				return cLogs.String()
 			}).Should(And(
-				ContainSubstring(`Reloading in progress ...`),
-				ContainSubstring(`reloading: execvp("php-fpm", {"php-fpm", "-y"`),
+				ContainSubstring(`process fpm stopped`),
+				ContainSubstring(`process httpd stopped`),
 			))
 			Eventually(container).Should(Serve(ContainSubstring("SUCCESS: date loads.")).OnPort(8080).WithEndpoint("/index.php?date"))
 		})
@@ -143,13 +143,14 @@ func testNginxReload(t *testing.T, context spec.G, it spec.S) {
 
 			Expect(logs).To(ContainLines(
 				"  Determining start commands to include in procs.yml:",
-				MatchRegexp(`    Nginx: watchexec --watch /workspace/\.nginx\.conf\.d --on-busy-update signal --signal SIGHUP --shell none -- nginx -p /workspace -c /workspace/nginx\.conf`),
-				MatchRegexp(`    FPM: watchexec --watch /workspace/\.php\.fpm\.d --on-busy-update signal --signal SIGUSR2 --shell none -- php-fpm -y /layers/.*/php-fpm-config/base.conf -c /layers/.*/php/etc`),
+				MatchRegexp(`    Nginx: nginx -p /workspace -c /workspace/nginx\.conf`),
+				MatchRegexp(`    FPM: php-fpm -y /layers/.*/php-fpm-config/base.conf -c /layers/.*/php/etc`),
 			))
 
 			Expect(logs).To(ContainLines(
 				"  Assigning launch processes:",
-				fmt.Sprintf("    web (default): procmgr-binary /layers/%s/php-start/procs.yml", strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
+				fmt.Sprintf("    web: procmgr-binary /layers/%s/php-start/procs.yml", strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
+				fmt.Sprintf("    reload-web (default): watchexec --restart --watch /workspace/.nginx.conf.d --watch /workspace/.php.fpm.d --shell none -- procmgr-binary /layers/%s/php-start/procs.yml", strings.ReplaceAll(buildpackInfo.Buildpack.ID, "/", "_")),
 			))
 
 			container, err = docker.Container.Run.
@@ -0,0 +1,112 @@
+package phpstart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/fs"
+)
+
+const (
+	LaravelQueue     = "laravel-queue"
+	LaravelScheduler = "laravel-scheduler"
+)
+
+// laravelWorkers returns the Laravel queue worker and scheduler processes
+// that are enabled with $BP_PHP_LARAVEL_QUEUE and $BP_PHP_LARAVEL_SCHEDULER.
+// They are only added for Laravel apps, which require laravel/framework in
+// their composer.json and have an artisan file.
+func laravelWorkers(workingDir string) ([]worker, error) {
+	queue, err := parseBoolEnv("BP_PHP_LARAVEL_QUEUE")
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler, err := parseBoolEnv("BP_PHP_LARAVEL_SCHEDULER")
+	if err != nil {
+		return nil, err
+	}
+
+	if !queue && !scheduler {
+		return nil, nil
+	}
+
+	requires, err := composerRequires(workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := requires["laravel/framework"]; !ok {
+		return nil, nil
+	}
+
+	artisan := filepath.Join(workingDir, "artisan")
+	if exists, err := fs.Exists(artisan); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, nil
+	}
+
+	var workers []worker
+	if queue {
+		args := []string{artisan, "queue:work"}
+		if connection := os.Getenv("BP_PHP_LARAVEL_QUEUE_CONNECTION"); connection != "" {
+			args = append(args, connection)
+		}
+
+		if queues := os.Getenv("BP_PHP_LARAVEL_QUEUE_QUEUES"); queues != "" {
+			args = append(args, fmt.Sprintf("--queue=%s", strings.Join(strings.Fields(strings.ReplaceAll(queues, ",", " ")), ",")))
+		}
+
+		for _, option := range []string{"sleep", "tries", "memory"} {
+			name := fmt.Sprintf("BP_PHP_LARAVEL_QUEUE_%s", strings.ToUpper(option))
+			value, ok := os.LookupEnv(name)
+			if !ok || value == "" {
+				continue
+			}
+
+			if _, err := strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("failed to parse %s value %s: %w", name, value, err)
+			}
+			args = append(args, fmt.Sprintf("--%s=%s", option, value))
+		}
+
+		proc := NewProc("php", append(args, "--no-interaction"))
+		// A queue worker finishes its current job when it receives SIGTERM, and
+		// picks up new code once it is told to restart.
+		proc.StopSignal = "SIGTERM"
+		proc.ReloadCommand = []string{"php", artisan, "queue:restart", "--no-interaction"}
+
+		workers = append(workers, worker{name: LaravelQueue, proc: proc})
+	}
+
+	if scheduler {
+		// schedule:work runs the due tasks every minute, and waits for the
+		// running tasks when it receives SIGTERM.
+		proc := NewProc("php", []string{artisan, "schedule:work", "--no-interaction"})
+		proc.StopSignal = "SIGTERM"
+
+		workers = append(workers, worker{name: LaravelScheduler, proc: proc})
+	}
+
+	return workers, nil
+}
+
+// parseBoolEnv returns the value of the given boolean env var, which is false
+// when it is not set.
+func parseBoolEnv(name string) (bool, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s value %s: %w", name, value, err)
+	}
+
+	return enabled, nil
+}
@@ -0,0 +1,29 @@
+package phpstart
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadLogFiles returns the log files of the named process from the
+// comma-separated BP_PHP_LOG_FILES_<NAME>, such as BP_PHP_LOG_FILES_FPM.
+// Relative paths are relative to the app in workingDir.
+func ReadLogFiles(workingDir, name string) []string {
+	envVar := processEnvName("BP_PHP_LOG_FILES_", name)
+
+	var paths []string
+	for _, path := range strings.Split(os.Getenv(envVar), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths
+}
@@ -0,0 +1,69 @@
+package phpstart
+
+import "github.com/paketo-buildpacks/packit/v2"
+
+// NginxServer runs Nginx in front of php-fpm, using the configuration
+// provided through $PHP_NGINX_PATH.
+type NginxServer struct{}
+
+func NewNginxServer() NginxServer {
+	return NginxServer{}
+}
+
+func (NginxServer) Name() string {
+	return Nginx
+}
+
+func (NginxServer) DisplayName() string {
+	return "Nginx"
+}
+
+func (NginxServer) Requirements() []packit.BuildPlanRequirement {
+	return []packit.BuildPlanRequirement{
+		{
+			Name: Nginx,
+			Metadata: BuildPlanMetadata{
+				Launch: true,
+			},
+		},
+		{
+			Name: PhpNginxConfig,
+			Metadata: BuildPlanMetadata{
+				Build:  true,
+				Launch: true,
+			},
+		},
+	}
+}
+
+func (NginxServer) ConfigEnvVar() string {
+	return "PHP_NGINX_PATH"
+}
+
+func (NginxServer) UsesFpm() bool {
+	return true
+}
+
+func (NginxServer) StartCommand(workingDir, configPath string) (Proc, error) {
+	return NewProc("nginx", []string{"-p", workingDir, "-c", configPath}), nil
+}
+
+// ReloadSignal is SIGHUP, which makes Nginx reload its configuration.
+// http://nginx.org/en/docs/control.html
+func (NginxServer) ReloadSignal() string {
+	return "SIGHUP"
+}
+
+func (NginxServer) ReloadDir() string {
+	return ".nginx.conf.d"
+}
+
+// StopSignal is SIGQUIT, which makes Nginx shut down gracefully.
+func (NginxServer) StopSignal() string {
+	return "SIGQUIT"
+}
+
+// ConfigTestCommand tests the Nginx configuration without starting Nginx.
+func (NginxServer) ConfigTestCommand(workingDir, configPath string) Proc {
+	return NewProc("nginx", []string{"-t", "-p", workingDir, "-c", configPath})
+}
@@ -0,0 +1,54 @@
+package phpstart
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/paketo-buildpacks/packit/v2/fs"
+)
+
+// errPhpFileFound stops the walk of the web root at the first PHP file.
+var errPhpFileFound = errors.New("found a PHP file")
+
+// isPhpApp reports whether the app in workingDir looks like a PHP app: it
+// has a composer.json, or the file named by $COMPOSER, PHP files in its web
+// root, or an htdocs directory. Setting $BP_PHP_START_ENABLED to true, or
+// naming a server with $BP_PHP_SERVER, opts in regardless.
+func isPhpApp(workingDir string) (bool, error) {
+	enabled, err := parseBoolEnv("BP_PHP_START_ENABLED")
+	if err != nil {
+		return false, err
+	}
+
+	if enabled || os.Getenv("BP_PHP_SERVER") != "" {
+		return true, nil
+	}
+
+	if exists, err := fs.Exists(composerJsonPath(workingDir)); err != nil || exists {
+		return exists, err
+	}
+
+	if exists, err := fs.Exists(filepath.Join(workingDir, "htdocs")); err != nil || exists {
+		return exists, err
+	}
+
+	err = filepath.WalkDir(webDir(workingDir), func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.IsDir() && filepath.Ext(path) == ".php" {
+			return errPhpFileFound
+		}
+
+		return nil
+	})
+	if errors.Is(err, errPhpFileFound) {
+		return true, nil
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	return false, nil
+}
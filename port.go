@@ -0,0 +1,123 @@
+package phpstart
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+const (
+	// ListenDirectiveEnv and IncludeDirectiveEnv are the launch env vars
+	// through which Build passes the PortBinding of the server to the
+	// port-binder exec.d binary.
+	ListenDirectiveEnv  = "PHP_START_LISTEN_DIRECTIVE"
+	IncludeDirectiveEnv = "PHP_START_INCLUDE_DIRECTIVE"
+)
+
+// PortBinder is implemented by servers whose listen port is fixed in the
+// configuration that is provided at build time. When $BP_PHP_BIND_PORT is
+// true, the port-binder exec.d binary rewrites a copy of that configuration
+// at launch to listen on the $PORT that the platform assigns.
+type PortBinder interface {
+	PortBinding() PortBinding
+}
+
+// PortBinding describes the directives of a server configuration that the
+// port-binder rewrites.
+type PortBinding struct {
+	// Listen is the directive that sets the listen port, such as "Listen"
+	// for HTTPD.
+	Listen string
+
+	// Include is the directive that includes other configurations relative
+	// to the directory of the configuration, such as "include" for Nginx, or
+	// empty when relative includes do not depend on where the configuration
+	// is.
+	Include string
+}
+
+// PortBinding rewrites the Listen directive. Relative Include paths are
+// relative to the ServerRoot.
+func (HttpdServer) PortBinding() PortBinding {
+	return PortBinding{Listen: "Listen"}
+}
+
+// PortBinding rewrites the listen directive, and the relative paths of
+// include directives.
+func (NginxServer) PortBinding() PortBinding {
+	return PortBinding{Listen: "listen", Include: "include"}
+}
+
+// BindPort replaces the port of the primary listen directive in the
+// configuration: the one that sets default_server, or else the first one.
+// Other listen directives, such as the ones of an admin server, are kept.
+// Directive names are matched regardless of case.
+func BindPort(config []byte, directive string, port int) []byte {
+	listen := regexp.MustCompile(`(?mi)^(\s*` + regexp.QuoteMeta(directive) + `\s+(?:\S+:)?)(\d+)([^\n]*)`)
+
+	matches := listen.FindAllSubmatchIndex(config, -1)
+	if len(matches) == 0 {
+		return config
+	}
+
+	primary := matches[0]
+	for _, match := range matches {
+		if bytes.Contains(config[match[6]:match[7]], []byte("default_server")) {
+			primary = match
+			break
+		}
+	}
+
+	var rewritten []byte
+	rewritten = append(rewritten, config[:primary[4]]...)
+	rewritten = append(rewritten, strconv.Itoa(port)...)
+	return append(rewritten, config[primary[5]:]...)
+}
+
+// ResolveIncludes makes the relative paths of the include directives in the
+// configuration absolute, relative to dir, so that a copy of the
+// configuration in another directory includes the same files.
+func ResolveIncludes(config []byte, directive, dir string) []byte {
+	include := regexp.MustCompile(`(?mi)^(\s*` + regexp.QuoteMeta(directive) + `\s+)([^\s;"'$/][^\s;]*)`)
+	return include.ReplaceAllFunc(config, func(match []byte) []byte {
+		parts := include.FindSubmatch(match)
+		return append(append([]byte{}, parts[1]...), filepath.Join(dir, string(parts[2]))...)
+	})
+}
+
+// ParsePort returns the given value of $PORT as a port number.
+func ParsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q: must be a number between 1 and 65535", value)
+	}
+
+	return port, nil
+}
+
+// WritePortConfig writes a copy of the configuration at configPath, with its
+// primary listen directive rewritten to the given port, to tmpDir and returns
+// its path. The configuration itself belongs to the layer of the buildpack
+// that provided it, so it is left as is.
+func WritePortConfig(configPath string, binding PortBinding, port int, tmpDir string) (string, error) {
+	config, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read server configuration: %w", err)
+	}
+
+	config = BindPort(config, binding.Listen, port)
+	if binding.Include != "" {
+		config = ResolveIncludes(config, binding.Include, filepath.Dir(configPath))
+	}
+
+	path := filepath.Join(tmpDir, fmt.Sprintf("port-%d-%s", port, filepath.Base(configPath)))
+	err = os.WriteFile(path, config, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write server configuration: %w", err)
+	}
+
+	return path, nil
+}
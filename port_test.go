@@ -0,0 +1,85 @@
+package phpstart_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testPort(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("BindPort", func() {
+		it("replaces the port of the first HTTPD Listen directive", func() {
+			config := phpstart.BindPort([]byte("ServerRoot /app\nListen 8080\n  listen 0.0.0.0:9090\n"), phpstart.NewHttpdServer().PortBinding().Listen, 3000)
+			Expect(string(config)).To(Equal("ServerRoot /app\nListen 3000\n  listen 0.0.0.0:9090\n"))
+		})
+
+		it("replaces the port of the Nginx listen directive of the default server", func() {
+			config := phpstart.BindPort([]byte("server {\n  listen 9090;\n}\nserver {\n  listen [::]:8080 default_server;\n  root /app;\n}\n"), phpstart.NewNginxServer().PortBinding().Listen, 3000)
+			Expect(string(config)).To(Equal("server {\n  listen 9090;\n}\nserver {\n  listen [::]:3000 default_server;\n  root /app;\n}\n"))
+		})
+
+		it("keeps a configuration without a listen directive", func() {
+			Expect(string(phpstart.BindPort([]byte("events {}\n"), "listen", 3000))).To(Equal("events {}\n"))
+		})
+	})
+
+	context("ResolveIncludes", func() {
+		it("makes relative include paths absolute", func() {
+			config := phpstart.ResolveIncludes([]byte("include mime.types;\ninclude /etc/nginx/conf.d/*.conf;\ninclude $app/extra.conf;\n"), "include", "/workspace")
+			Expect(string(config)).To(Equal("include /workspace/mime.types;\ninclude /etc/nginx/conf.d/*.conf;\ninclude $app/extra.conf;\n"))
+		})
+	})
+
+	context("ParsePort", func() {
+		it("parses the port", func() {
+			Expect(phpstart.ParsePort("3000")).To(Equal(3000))
+		})
+
+		it("rejects values that are not a port", func() {
+			_, err := phpstart.ParsePort("http")
+			Expect(err).To(MatchError(`invalid port "http": must be a number between 1 and 65535`))
+
+			_, err = phpstart.ParsePort("70000")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	context("WritePortConfig", func() {
+		var (
+			configDir string
+			tmpDir    string
+		)
+
+		it.Before(func() {
+			configDir = t.TempDir()
+			tmpDir = t.TempDir()
+			Expect(os.WriteFile(filepath.Join(configDir, "nginx.conf"), []byte("include mime.types;\nlisten 8080;\n"), 0644)).To(Succeed())
+		})
+
+		it("writes the rewritten configuration to the tmp dir", func() {
+			path, err := phpstart.WritePortConfig(filepath.Join(configDir, "nginx.conf"), phpstart.NewNginxServer().PortBinding(), 3000, tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal(filepath.Join(tmpDir, "port-3000-nginx.conf")))
+
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("include " + filepath.Join(configDir, "mime.types") + ";\nlisten 3000;\n"))
+
+			Expect(filepath.Join(configDir, "port-3000-nginx.conf")).NotTo(BeAnExistingFile())
+		})
+
+		context("when the configuration cannot be read", func() {
+			it("returns an error", func() {
+				_, err := phpstart.WritePortConfig(filepath.Join(configDir, "missing.conf"), phpstart.NewNginxServer().PortBinding(), 3000, tmpDir)
+				Expect(err).To(MatchError(ContainSubstring("failed to read server configuration")))
+			})
+		})
+	})
+}
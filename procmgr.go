@@ -4,19 +4,119 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"syscall"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
 
+// ProcsSchemaVersion is the version of the procs.yml format that is written
+// by the buildpack and read by the procmgr-binary.
+const ProcsSchemaVersion = 1
+
 // Procs is the existing list of process names and commands to run
 type Procs struct {
 	Processes map[string]Proc
+
+	// Schedules are commands that are run periodically, see Schedule.
+	Schedules map[string]Schedule `yaml:"schedules,omitempty"`
 }
 
 // Proc is a single process to run
 type Proc struct {
 	Command string
 	Args    []string
+
+	// ReloadSignal is sent to the process to gracefully reload it when the
+	// process manager receives SIGHUP.
+	ReloadSignal string `yaml:"reload_signal,omitempty"`
+
+	// StopSignal is sent to the process to gracefully stop it when the
+	// process manager receives SIGTERM or SIGINT. Defaults to SIGTERM.
+	StopSignal string `yaml:"stop_signal,omitempty"`
+
+	// ReloadCommand is run to gracefully reload the process when the process
+	// manager receives SIGHUP.
+	ReloadCommand []string `yaml:"reload_command,omitempty"`
+
+	// HealthCheck describes how to probe whether the process is healthy.
+	HealthCheck *HealthCheck `yaml:"health_check,omitempty"`
+
+	// Restart makes the process manager start the process again when it
+	// exits, instead of stopping all other processes. This suits workers that
+	// exit on purpose, for example after a time or memory limit.
+	Restart bool `yaml:"restart,omitempty"`
+
+	// Init makes the process a one-shot task, such as a database migration,
+	// that runs next to the others. The processes are only reported ready
+	// once it exits with 0, and the process manager stops when it fails.
+	Init bool `yaml:"init,omitempty"`
+
+	// Watch lists the paths whose changes send the process a signal, when
+	// the process manager runs with live reload enabled.
+	Watch []WatchPath `yaml:"watch,omitempty"`
+
+	// StartupTimeout is the duration, such as "30s", within which the process
+	// has to pass its health check after it starts. The process manager stops
+	// when it does not. Processes are not limited by default.
+	StartupTimeout string `yaml:"startup_timeout,omitempty"`
+
+	// LogFiles lists files that the process writes logs to, such as the
+	// php-fpm slowlog, which the process manager follows and writes to stdout
+	// prefixed with the name of the process.
+	LogFiles []string `yaml:"log_files,omitempty"`
+}
+
+// StartupTimeoutDuration returns the duration within which the process has to
+// pass its health check, which is zero when it is not limited.
+func (p Proc) StartupTimeoutDuration() (time.Duration, error) {
+	return ParseStartupTimeout(p.StartupTimeout)
+}
+
+// ParseStartupTimeout returns the given startup timeout as a duration, which
+// is zero when the value is empty.
+func ParseStartupTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid startup timeout %q: must be a positive duration", value)
+	}
+
+	return timeout, nil
+}
+
+// HealthCheck describes how to probe a running process.
+type HealthCheck struct {
+	// URL is an HTTP endpoint that responds with a 2xx status when the
+	// process is healthy.
+	URL string `yaml:"url,omitempty"`
+
+	// Socket is a TCP address, such as 127.0.0.1:9000, or the path of a unix
+	// socket, that accepts connections when the process is healthy.
+	Socket string `yaml:"socket,omitempty"`
+
+	// Ping is a path that the FastCGI server at Socket answers when the
+	// process is healthy, such as the ping.path of a php-fpm pool.
+	Ping string `yaml:"ping,omitempty"`
+}
+
+// ProcessTable is the resolved list of processes that is published in the
+// php-start layer metadata and in an image label, so that an image can be
+// inspected without reading its procs.yml.
+type ProcessTable struct {
+	SchemaVersion int                          `json:"procs_schema_version" toml:"procs_schema_version"`
+	Server        string                       `json:"server" toml:"server"`
+	Processes     map[string]ProcessTableEntry `json:"processes" toml:"processes"`
+}
+
+// ProcessTableEntry is a single process in a ProcessTable.
+type ProcessTableEntry struct {
+	Command string   `json:"command" toml:"command"`
+	Args    []string `json:"args" toml:"args"`
+	Reload  bool     `json:"reload" toml:"reload"`
 }
 
 func NewProc(command string, args []string) Proc {
@@ -26,6 +126,7 @@ func NewProc(command string, args []string) Proc {
 func NewProcs() Procs {
 	return Procs{
 		Processes: map[string]Proc{},
+		Schedules: map[string]Schedule{},
 	}
 }
 
@@ -34,6 +135,11 @@ func (procs Procs) Add(procName string, newProc Proc) {
 	procs.Processes[procName] = newProc
 }
 
+// AddSchedule takes a schedule and a name, and adds it to the schedule list.
+func (procs Procs) AddSchedule(name string, schedule Schedule) {
+	procs.Schedules[name] = schedule
+}
+
 // WriteFile writes a Procs process list into YAML onto the given path
 func (procs Procs) WriteFile(path string) error {
 	bytes, err := yaml.Marshal(procs)
@@ -75,3 +181,24 @@ func ReadProcs(path string) (Procs, error) {
 
 	return procs, nil
 }
+
+var signals = map[string]syscall.Signal{
+	"SIGHUP":   syscall.SIGHUP,
+	"SIGINT":   syscall.SIGINT,
+	"SIGQUIT":  syscall.SIGQUIT,
+	"SIGKILL":  syscall.SIGKILL,
+	"SIGUSR1":  syscall.SIGUSR1,
+	"SIGUSR2":  syscall.SIGUSR2,
+	"SIGTERM":  syscall.SIGTERM,
+	"SIGWINCH": syscall.SIGWINCH,
+}
+
+// ParseSignal returns the signal with the given name, such as "SIGHUP", as
+// used for the reload and stop signals of a Proc.
+func ParseSignal(name string) (syscall.Signal, error) {
+	signal, ok := signals[name]
+	if !ok {
+		return 0, fmt.Errorf("unsupported signal %q", name)
+	}
+	return signal, nil
+}
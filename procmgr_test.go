@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	phpstart "github.com/paketo-buildpacks/php-start"
@@ -97,7 +98,7 @@ func testProcmgrLib(t *testing.T, context spec.G, it spec.S) {
 				procs, err := phpstart.ReadProcs(procsFilePath)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(procs.Processes)).To(Equal(1))
-				Expect(procs.Processes["echo1"]).To(Equal(phpstart.Proc{"echo", []string{"'Hello World!'"}}))
+				Expect(procs.Processes["echo1"]).To(Equal(phpstart.Proc{Command: "echo", Args: []string{"'Hello World!'"}}))
 			})
 		})
 
@@ -142,4 +143,23 @@ func testProcmgrLib(t *testing.T, context spec.G, it spec.S) {
 			})
 		})
 	})
+
+	context("StartupTimeoutDuration", func() {
+		it("returns the startup timeout of the process", func() {
+			timeout, err := phpstart.Proc{StartupTimeout: "30s"}.StartupTimeoutDuration()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timeout).To(Equal(30 * time.Second))
+		})
+
+		it("returns zero when the process is not limited", func() {
+			timeout, err := phpstart.Proc{}.StartupTimeoutDuration()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timeout).To(BeZero())
+		})
+
+		it("returns an error when the startup timeout is not a positive duration", func() {
+			_, err := phpstart.Proc{StartupTimeout: "0s"}.StartupTimeoutDuration()
+			Expect(err).To(MatchError(`invalid startup timeout "0s": must be a positive duration`))
+		})
+	})
 }
@@ -14,9 +14,10 @@ func main() {
 	logEmitter := scribe.NewEmitter(os.Stdout).WithLevel(os.Getenv("BP_LOG_LEVEL"))
 
 	reloader := watchexec.NewWatchexecReloader()
+	servers := phpstart.DefaultServerRegistry()
 
 	packit.Run(
-		phpstart.Detect(reloader),
-		phpstart.Build(procMgr, logEmitter, reloader),
+		phpstart.Detect(reloader, servers),
+		phpstart.Build(procMgr, logEmitter, reloader, servers, phpstart.NewExecConfigChecker(logEmitter), sbomGenerator{}),
 	)
 }
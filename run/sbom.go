@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/paketo-buildpacks/packit/v2"
+	"github.com/paketo-buildpacks/packit/v2/sbom"
+)
+
+// sbomGenerator generates SBOMs by scanning the given path with Syft.
+type sbomGenerator struct{}
+
+func (sbomGenerator) Generate(path string, formats []string) (packit.SBOMFormatter, error) {
+	content, err := sbom.Generate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return content.InFormats(formats...)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2/sbom"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSBOMGenerator(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		path = filepath.Join(t.TempDir(), "procmgr-binary")
+		Expect(os.WriteFile(path, []byte("some-binary"), 0755)).To(Succeed())
+	})
+
+	it("scans the path in the given formats", func() {
+		formatter, err := sbomGenerator{}.Generate(path, []string{sbom.CycloneDXFormat, sbom.SyftFormat})
+		Expect(err).NotTo(HaveOccurred())
+
+		var extensions []string
+		for _, format := range formatter.Formats() {
+			extensions = append(extensions, format.Extension)
+		}
+		Expect(extensions).To(Equal([]string{"cdx.json", "syft.json"}))
+	})
+
+	context("when a format is not supported", func() {
+		it("returns an error", func() {
+			_, err := sbomGenerator{}.Generate(path, []string{"application/unknown"})
+			Expect(err).To(MatchError("unsupported SBOM format: 'application/unknown'"))
+		})
+	})
+}
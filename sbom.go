@@ -0,0 +1,15 @@
+package phpstart
+
+import (
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+//go:generate faux --interface SBOMGenerator --output fakes/sbom_generator.go
+
+// SBOMGenerator generates the SBOM of the procmgr-binary that is copied into
+// the php-start layer, in the given formats. The generator that scans with
+// Syft is part of the buildpack binary in run/, rather than this package,
+// since the binaries in cmd/ import this package and would link Syft as well.
+type SBOMGenerator interface {
+	Generate(path string, formats []string) (packit.SBOMFormatter, error)
+}
@@ -0,0 +1,138 @@
+package phpstart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	// Time zones of schedules are resolved without relying on the zoneinfo
+	// of the run image
+	_ "time/tzdata"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Schedule is a command that the procmgr-binary runs periodically, such as
+// "wp cron event run --due-now".
+type Schedule struct {
+	// Cron is a five-field cron expression, see ParseCron.
+	Cron string `yaml:"cron"`
+
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args,omitempty"`
+
+	// Timezone is the IANA time zone that Cron is evaluated in. Defaults to
+	// UTC.
+	Timezone string `yaml:"timezone,omitempty"`
+
+	// Timeout is the duration, such as "5m", after which a run is killed.
+	// Runs are not limited by default.
+	Timeout string `yaml:"timeout,omitempty"`
+
+	// AllowOverlap allows a run to start while the previous run is still
+	// running. Such runs are skipped by default.
+	AllowOverlap bool `yaml:"allow_overlap,omitempty"`
+}
+
+// CronSchedule returns the parsed cron expression of the schedule.
+func (s Schedule) CronSchedule() (CronSchedule, error) {
+	return ParseCron(s.Cron)
+}
+
+// Location returns the time zone that the schedule is evaluated in.
+func (s Schedule) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+	}
+
+	return location, nil
+}
+
+// TimeoutDuration returns the duration after which a run is killed, which is
+// zero when runs are not limited.
+func (s Schedule) TimeoutDuration() (time.Duration, error) {
+	if s.Timeout == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(s.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must be a positive duration", s.Timeout)
+	}
+
+	return timeout, nil
+}
+
+// Validate checks that the schedule can be run by the procmgr-binary.
+func (s Schedule) Validate() error {
+	if s.Command == "" {
+		return fmt.Errorf("missing command")
+	}
+
+	if _, err := s.CronSchedule(); err != nil {
+		return err
+	}
+
+	if _, err := s.Location(); err != nil {
+		return err
+	}
+
+	if _, err := s.TimeoutDuration(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReadSchedules reads the schedules that an app declares in a YAML file
+// with a top-level "schedules" map. A missing file has no schedules.
+func ReadSchedules(path string) (map[string]Schedule, error) {
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]Schedule{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+
+	var file struct {
+		Schedules map[string]Schedule `yaml:"schedules"`
+	}
+	err = yaml.UnmarshalStrict(contents, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schedules: %w", err)
+	}
+
+	if file.Schedules == nil {
+		return map[string]Schedule{}, nil
+	}
+
+	return file.Schedules, nil
+}
+
+// schedulesPath returns the location of the app's schedules file, which can
+// be overridden with $BP_PHP_CRON_FILE.
+func schedulesPath(workingDir string) string {
+	if value, ok := os.LookupEnv("BP_PHP_CRON_FILE"); ok && value != "" {
+		return filepath.Join(workingDir, value)
+	}
+
+	return filepath.Join(workingDir, ".php-cron.yml")
+}
+
+// sortedScheduleNames returns the names of the given schedules in order, so
+// that they are logged in a stable order.
+func sortedScheduleNames(schedules map[string]Schedule) []string {
+	var names []string
+	for name := range schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,79 @@
+package phpstart_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testSchedule(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		path string
+	)
+
+	it.Before(func() {
+		path = filepath.Join(t.TempDir(), ".php-cron.yml")
+	})
+
+	context("ReadSchedules", func() {
+		it("reads the schedules from the file", func() {
+			Expect(os.WriteFile(path, []byte(`schedules:
+  wp-cron:
+    cron: "*/5 * * * *"
+    command: wp
+    args: [cron, event, run, --due-now]
+    timezone: Europe/Berlin
+    timeout: 5m
+`), 0600)).To(Succeed())
+
+			schedules, err := phpstart.ReadSchedules(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedules).To(Equal(map[string]phpstart.Schedule{
+				"wp-cron": {
+					Cron:     "*/5 * * * *",
+					Command:  "wp",
+					Args:     []string{"cron", "event", "run", "--due-now"},
+					Timezone: "Europe/Berlin",
+					Timeout:  "5m",
+				},
+			}))
+		})
+
+		it("returns no schedules when the file does not exist", func() {
+			schedules, err := phpstart.ReadSchedules(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedules).To(BeEmpty())
+		})
+
+		context("when the file cannot be parsed", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(path, []byte("schedules:\n  wp-cron:\n    every: minute\n"), 0600)).To(Succeed())
+			})
+
+			it("returns an error", func() {
+				_, err := phpstart.ReadSchedules(path)
+				Expect(err).To(MatchError(ContainSubstring("failed to parse schedules")))
+			})
+		})
+	})
+
+	context("Validate", func() {
+		it("accepts a valid schedule", func() {
+			Expect(phpstart.Schedule{Cron: "@hourly", Command: "php", Timezone: "America/New_York", Timeout: "30s"}.Validate()).To(Succeed())
+		})
+
+		it("rejects invalid schedules", func() {
+			Expect(phpstart.Schedule{Cron: "@hourly"}.Validate()).To(MatchError("missing command"))
+			Expect(phpstart.Schedule{Cron: "hourly", Command: "php"}.Validate()).To(MatchError(ContainSubstring("invalid cron expression")))
+			Expect(phpstart.Schedule{Cron: "@hourly", Command: "php", Timezone: "Mars/Olympus"}.Validate()).To(MatchError(ContainSubstring("invalid timezone")))
+			Expect(phpstart.Schedule{Cron: "@hourly", Command: "php", Timeout: "-1s"}.Validate()).To(MatchError(ContainSubstring("invalid timeout")))
+		})
+	})
+}
@@ -0,0 +1,217 @@
+package phpstart
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2"
+)
+
+//go:generate faux --interface Server --output fakes/server.go
+
+// Server is a web server or application server that serves a PHP app. Detect
+// and Build look up the servers they support in a ServerRegistry, so that a
+// server can be added without changes to either of them.
+type Server interface {
+	// Name identifies the server in $BP_PHP_SERVER, the build logs and
+	// procs.yml.
+	Name() string
+
+	// DisplayName labels the start command of the server in the build logs,
+	// such as "Nginx".
+	DisplayName() string
+
+	// Requirements are the build plan requirements needed to run the server.
+	// Servers that use php-fpm are also given the "php" and "php-fpm"
+	// requirements.
+	Requirements() []packit.BuildPlanRequirement
+
+	// ConfigEnvVar is the environment variable through which another
+	// buildpack provides the server configuration at build time, such as
+	// "PHP_HTTPD_PATH". Servers with a config env var are offered in every
+	// build plan, while servers without one need to be selected through
+	// $BP_PHP_SERVER or detected from the app.
+	ConfigEnvVar() string
+
+	// UsesFpm reports whether the server relies on php-fpm to run PHP.
+	UsesFpm() bool
+
+	// StartCommand returns the process that starts the server for the app in
+	// workingDir, using the server configuration at configPath.
+	StartCommand(workingDir, configPath string) (Proc, error)
+
+	// ReloadSignal is the signal that makes the server reload its
+	// configuration, or empty if the server cannot be reloaded with a signal.
+	ReloadSignal() string
+
+	// ReloadDir is the directory, relative to the app, that is watched for
+	// configuration changes when live reload is enabled, or empty if the
+	// server has none.
+	ReloadDir() string
+
+	// StopSignal is the signal that gracefully stops the server.
+	StopSignal() string
+}
+
+// ComposerDetector is implemented by servers that can be detected from the
+// packages required in an app's composer.json.
+type ComposerDetector interface {
+	// DetectComposer reports whether the packages that the composer.json
+	// requires, mapped to their version constraints, indicate the server.
+	DetectComposer(requires map[string]string) bool
+}
+
+// ServerRegistry is an ordered collection of the servers that Detect and
+// Build support.
+type ServerRegistry struct {
+	servers []Server
+}
+
+// NewServerRegistry returns a registry containing the given servers, in
+// order.
+func NewServerRegistry(servers ...Server) *ServerRegistry {
+	registry := &ServerRegistry{}
+	for _, server := range servers {
+		registry.Register(server)
+	}
+	return registry
+}
+
+// DefaultServerRegistry returns a registry of every server supported by this
+// buildpack.
+func DefaultServerRegistry() *ServerRegistry {
+	return NewServerRegistry(
+		NewHttpdServer(),
+		NewNginxServer(),
+		NewCaddyServer(),
+		NewBuiltinServer(),
+		NewSwooleServer(),
+		NewRoadRunnerServer(),
+		NewFrankenPHPServer(),
+	)
+}
+
+// Register adds a server to the registry, replacing any registered server
+// with the same name.
+func (r *ServerRegistry) Register(server Server) {
+	for i, registered := range r.servers {
+		if registered.Name() == server.Name() {
+			r.servers[i] = server
+			return
+		}
+	}
+	r.servers = append(r.servers, server)
+}
+
+// Get returns the registered server with the given name.
+func (r *ServerRegistry) Get(name string) (Server, bool) {
+	for _, server := range r.servers {
+		if server.Name() == name {
+			return server, true
+		}
+	}
+	return nil, false
+}
+
+// Servers returns the registered servers, in order.
+func (r *ServerRegistry) Servers() []Server {
+	return append([]Server{}, r.servers...)
+}
+
+// selectedServer returns the server named by $BP_PHP_SERVER, or else the
+// server detected from the packages required in the app's composer.json.
+func (r *ServerRegistry) selectedServer(workingDir string) (Server, bool, error) {
+	if server, found, err := r.explicitServer(); err != nil || found {
+		return server, found, err
+	}
+
+	requires, err := composerRequires(workingDir)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, server := range r.servers {
+		detector, ok := server.(ComposerDetector)
+		if !ok {
+			continue
+		}
+
+		if detector.DetectComposer(requires) {
+			return server, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// explicitServer returns the server named by $BP_PHP_SERVER, if it is set,
+// and fails when the registry has no server with that name.
+func (r *ServerRegistry) explicitServer() (Server, bool, error) {
+	name, ok := os.LookupEnv("BP_PHP_SERVER")
+	if !ok || name == "" {
+		return nil, false, nil
+	}
+
+	server, found := r.Get(name)
+	if !found {
+		var names []string
+		for _, server := range r.servers {
+			names = append(names, server.Name())
+		}
+		return nil, false, fmt.Errorf("unsupported BP_PHP_SERVER value %q, must be one of: %s", name, strings.Join(names, ", "))
+	}
+
+	return server, true, nil
+}
+
+// buildServer returns the server that Build should start, along with the
+// path to its configuration. A server named by $BP_PHP_SERVER is always used,
+// and fails when its config env var was not set by another buildpack.
+// Otherwise the server whose config env var was set by another buildpack is
+// used, falling back to a server detected from the app's composer.json.
+func (r *ServerRegistry) buildServer(workingDir string) (Server, string, error) {
+	if server, found, err := r.explicitServer(); err != nil {
+		return nil, "", err
+	} else if found {
+		if server.ConfigEnvVar() == "" {
+			return server, "", nil
+		}
+
+		configPath := os.Getenv(server.ConfigEnvVar())
+		if configPath == "" {
+			return nil, "", fmt.Errorf("failed to lookup $%s", server.ConfigEnvVar())
+		}
+		return server, configPath, nil
+	}
+
+	var configured []Server
+	var configEnvVars []string
+	for _, server := range r.servers {
+		if server.ConfigEnvVar() == "" {
+			continue
+		}
+
+		configEnvVars = append(configEnvVars, fmt.Sprintf("$%s", server.ConfigEnvVar()))
+		if os.Getenv(server.ConfigEnvVar()) != "" {
+			configured = append(configured, server)
+		}
+	}
+
+	if len(configured) == 1 {
+		return configured[0], os.Getenv(configured[0].ConfigEnvVar()), nil
+	}
+
+	if len(configured) == 0 {
+		server, found, err := r.selectedServer(workingDir)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if found {
+			return server, "", nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("need exactly one of: %s", strings.Join(configEnvVars, " or "))
+}
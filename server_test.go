@@ -0,0 +1,212 @@
+package phpstart_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paketo-buildpacks/packit/v2"
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/paketo-buildpacks/php-start/fakes"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testServer(t *testing.T, context spec.G, it spec.S) {
+	var (
+		Expect = NewWithT(t).Expect
+
+		workingDir string
+	)
+
+	it.Before(func() {
+		workingDir = t.TempDir()
+	})
+
+	context("ServerRegistry", func() {
+		var (
+			registry *phpstart.ServerRegistry
+			server   *fakes.Server
+		)
+
+		it.Before(func() {
+			server = &fakes.Server{}
+			server.NameCall.Returns.String = "some-server"
+
+			registry = phpstart.NewServerRegistry(phpstart.NewHttpdServer(), server)
+		})
+
+		it("returns the registered servers in order", func() {
+			Expect(registry.Servers()).To(Equal([]phpstart.Server{phpstart.NewHttpdServer(), server}))
+
+			found, ok := registry.Get("some-server")
+			Expect(ok).To(BeTrue())
+			Expect(found).To(Equal(server))
+
+			_, ok = registry.Get("missing-server")
+			Expect(ok).To(BeFalse())
+		})
+
+		context("when a server with the same name is registered", func() {
+			it("replaces the existing server", func() {
+				replacement := &fakes.Server{}
+				replacement.NameCall.Returns.String = "httpd"
+
+				registry.Register(replacement)
+				Expect(registry.Servers()).To(Equal([]phpstart.Server{replacement, server}))
+			})
+		})
+
+		context("DefaultServerRegistry", func() {
+			it("contains every supported server", func() {
+				var names []string
+				for _, server := range phpstart.DefaultServerRegistry().Servers() {
+					names = append(names, server.Name())
+				}
+				Expect(names).To(Equal([]string{"httpd", "nginx", "caddy", "builtin", "swoole", "roadrunner", "frankenphp"}))
+			})
+		})
+	})
+
+	context("HttpdServer", func() {
+		it("starts HTTPD in the foreground", func() {
+			server := phpstart.NewHttpdServer()
+			Expect(server.ConfigEnvVar()).To(Equal("PHP_HTTPD_PATH"))
+			Expect(server.UsesFpm()).To(BeTrue())
+			Expect(server.ReloadDir()).To(Equal(".httpd.conf.d"))
+			Expect(server.ReloadSignal()).To(Equal("SIGHUP"))
+			Expect(server.StopSignal()).To(Equal("SIGWINCH"))
+
+			proc, err := server.StartCommand(workingDir, "httpd-conf-path")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(proc).To(Equal(phpstart.NewProc("httpd", []string{"-f", "httpd-conf-path", "-k", "start", "-DFOREGROUND"})))
+			Expect(server.ConfigTestCommand(workingDir, "httpd-conf-path")).To(Equal(phpstart.NewProc("httpd", []string{"-t", "-f", "httpd-conf-path"})))
+		})
+	})
+
+	context("NginxServer", func() {
+		it("starts Nginx with the app as its prefix", func() {
+			server := phpstart.NewNginxServer()
+			Expect(server.ConfigEnvVar()).To(Equal("PHP_NGINX_PATH"))
+			Expect(server.UsesFpm()).To(BeTrue())
+			Expect(server.ReloadDir()).To(Equal(".nginx.conf.d"))
+			Expect(server.ReloadSignal()).To(Equal("SIGHUP"))
+			Expect(server.StopSignal()).To(Equal("SIGQUIT"))
+
+			proc, err := server.StartCommand(workingDir, "nginx-conf-path")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(proc).To(Equal(phpstart.NewProc("nginx", []string{"-p", workingDir, "-c", "nginx-conf-path"})))
+			Expect(server.ConfigTestCommand(workingDir, "nginx-conf-path")).To(Equal(phpstart.NewProc("nginx", []string{"-t", "-p", workingDir, "-c", "nginx-conf-path"})))
+		})
+	})
+
+	context("CaddyServer", func() {
+		it("runs Caddy and reloads it through the caddy reload command", func() {
+			server := phpstart.NewCaddyServer()
+			Expect(server.ConfigEnvVar()).To(Equal("PHP_CADDY_PATH"))
+			Expect(server.UsesFpm()).To(BeTrue())
+			Expect(server.ReloadDir()).To(Equal(".caddy.conf.d"))
+			Expect(server.ReloadSignal()).To(BeEmpty())
+			Expect(server.StopSignal()).To(Equal("SIGTERM"))
+			Expect(server.Requirements()).To(Equal([]packit.BuildPlanRequirement{
+				{
+					Name: phpstart.Caddy,
+					Metadata: phpstart.BuildPlanMetadata{
+						Launch: true,
+					},
+				},
+				{
+					Name: phpstart.PhpCaddyConfig,
+					Metadata: phpstart.BuildPlanMetadata{
+						Build:  true,
+						Launch: true,
+					},
+				},
+			}))
+
+			proc, err := server.StartCommand(workingDir, "caddy-conf-path")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(proc).To(Equal(phpstart.Proc{
+				Command:       "caddy",
+				Args:          []string{"run", "--config", "caddy-conf-path"},
+				ReloadCommand: []string{"caddy", "reload", "--config", "caddy-conf-path"},
+			}))
+			Expect(server.ConfigTestCommand(workingDir, "caddy-conf-path")).To(Equal(phpstart.NewProc("caddy", []string{"validate", "--config", "caddy-conf-path"})))
+		})
+	})
+
+	context("BuiltinServer", func() {
+		it("serves the web directory on $PORT", func() {
+			server := phpstart.NewBuiltinServer()
+			Expect(server.ConfigEnvVar()).To(BeEmpty())
+			Expect(server.UsesFpm()).To(BeFalse())
+			Expect(server.ReloadDir()).To(BeEmpty())
+
+			proc, err := server.StartCommand(workingDir, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(proc).To(Equal(phpstart.NewProc("php", []string{"-S", "0.0.0.0:${PORT}", "-t", filepath.Join(workingDir, "htdocs")})))
+		})
+	})
+
+	context("AppServer", func() {
+		it("is detected from composer packages", func() {
+			server := phpstart.NewRoadRunnerServer()
+			Expect(server.DetectComposer(map[string]string{"spiral/roadrunner-http": "^3.0"})).To(BeTrue())
+			Expect(server.DetectComposer(map[string]string{"laravel/framework": "^11.0"})).To(BeFalse())
+			Expect(server.ConfigEnvVar()).To(BeEmpty())
+			Expect(server.UsesFpm()).To(BeFalse())
+			Expect(server.StopSignal()).To(Equal("SIGTERM"))
+		})
+
+		context("Swoole", func() {
+			it("is only detected when a Laravel Octane app requires Swoole", func() {
+				server := phpstart.NewSwooleServer()
+				Expect(server.DetectComposer(map[string]string{"laravel/octane": "^2.0", "ext-swoole": "*"})).To(BeTrue())
+				Expect(server.DetectComposer(map[string]string{"laravel/octane": "^2.0", "openswoole/ide-helper": "*"})).To(BeTrue())
+				Expect(server.DetectComposer(map[string]string{"laravel/octane": "^2.0"})).To(BeFalse())
+				Expect(server.DetectComposer(map[string]string{"laravel/octane": "^2.0", "spiral/roadrunner-http": "^3.0", "spiral/roadrunner-cli": "^2.5"})).To(BeFalse())
+				Expect(server.DetectComposer(map[string]string{"ext-swoole": "*"})).To(BeFalse())
+			})
+		})
+
+		context("when a custom app server is created", func() {
+			it("starts the given process", func() {
+				server := phpstart.NewAppServer("some-server", nil, []string{"some/package"}, []packit.BuildPlanRequirement{{Name: "some-requirement"}}, func(workingDir string) (phpstart.Proc, error) {
+					return phpstart.NewProc("some-command", []string{workingDir}), nil
+				})
+				Expect(server.Name()).To(Equal("some-server"))
+				Expect(server.DisplayName()).To(Equal("SOME-SERVER"))
+				Expect(server.Requirements()).To(Equal([]packit.BuildPlanRequirement{{Name: "some-requirement"}}))
+
+				proc, err := server.StartCommand(workingDir, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(proc).To(Equal(phpstart.NewProc("some-command", []string{workingDir})))
+			})
+
+			it("is only detected when the app requires each of its required packages", func() {
+				server := phpstart.NewAppServer("some-server", []string{"some/framework"}, []string{"some/package"}, nil, nil)
+				Expect(server.DetectComposer(map[string]string{"some/framework": "*", "some/package": "*"})).To(BeTrue())
+				Expect(server.DetectComposer(map[string]string{"some/package": "*"})).To(BeFalse())
+			})
+		})
+
+		context("when the app requires a composer package of the app server", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(filepath.Join(workingDir, "composer.json"), []byte(`{"require": {"runtime/frankenphp-symfony": "*"}}`), 0600)).To(Succeed())
+			})
+
+			it("is offered by Detect", func() {
+				detect := phpstart.Detect(&fakes.Reloader{}, phpstart.DefaultServerRegistry())
+				result, err := detect(packit.DetectContext{WorkingDir: workingDir})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Plan.Requires).To(ContainElement(packit.BuildPlanRequirement{
+					Name: phpstart.FrankenPHP,
+					Metadata: phpstart.BuildPlanMetadata{
+						Launch: true,
+					},
+				}))
+			})
+		})
+	})
+}
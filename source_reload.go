@@ -0,0 +1,60 @@
+package phpstart
+
+import (
+	"os"
+	"strings"
+)
+
+// SourceReload configures the watch on the application source tree that
+// gracefully reloads php-fpm, which flushes opcache, when a source file
+// changes. This keeps changes visible in images that turn off opcache
+// timestamp validation.
+type SourceReload struct {
+	Enabled bool
+	Include []string
+	Exclude []string
+}
+
+// ReadSourceReload returns the source reload configuration from
+// $BP_PHP_SOURCE_RELOAD and the comma-separated globs, relative to the app
+// directory, in $BP_PHP_SOURCE_RELOAD_INCLUDE and
+// $BP_PHP_SOURCE_RELOAD_EXCLUDE.
+func ReadSourceReload() (SourceReload, error) {
+	enabled, err := parseBoolEnv("BP_PHP_SOURCE_RELOAD")
+	if err != nil {
+		return SourceReload{}, err
+	}
+
+	return SourceReload{
+		Enabled: enabled,
+		Include: globsEnv("BP_PHP_SOURCE_RELOAD_INCLUDE", "**/*.php"),
+		Exclude: globsEnv("BP_PHP_SOURCE_RELOAD_EXCLUDE", "vendor/**,var/cache/**"),
+	}, nil
+}
+
+// WatchPath returns the watch on the source tree of the app in appDir, which
+// sends php-fpm SIGUSR2.
+func (s SourceReload) WatchPath(appDir string) WatchPath {
+	return WatchPath{
+		Path:    appDir,
+		Signal:  "SIGUSR2",
+		Include: s.Include,
+		Ignore:  s.Exclude,
+	}
+}
+
+func globsEnv(name, fallback string) []string {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		value = fallback
+	}
+
+	var globs []string
+	for _, glob := range strings.Split(value, ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+
+	return globs
+}
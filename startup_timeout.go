@@ -0,0 +1,20 @@
+package phpstart
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadStartupTimeout returns the startup timeout of the named process from
+// BP_PHP_STARTUP_TIMEOUT_<NAME>, such as BP_PHP_STARTUP_TIMEOUT_FPM.
+func ReadStartupTimeout(name string) (string, error) {
+	envVar := processEnvName("BP_PHP_STARTUP_TIMEOUT_", name)
+
+	timeout := strings.TrimSpace(os.Getenv(envVar))
+	if _, err := ParseStartupTimeout(timeout); err != nil {
+		return "", fmt.Errorf("failed to parse %s value %s: %w", envVar, timeout, err)
+	}
+
+	return timeout, nil
+}
@@ -0,0 +1,95 @@
+package phpstart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/fs"
+)
+
+const SymfonyMessenger = "symfony-messenger"
+
+// symfonyWorkers returns the Symfony Messenger consumer processes that are
+// enabled with $BP_PHP_SYMFONY_MESSENGER. They are only added for Symfony apps
+// that require symfony/messenger in their composer.json and have a
+// bin/console file.
+func symfonyWorkers(workingDir string) ([]worker, error) {
+	enabled, err := parseBoolEnv("BP_PHP_SYMFONY_MESSENGER")
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	requires, err := composerRequires(workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := requires["symfony/messenger"]; !ok {
+		return nil, nil
+	}
+
+	console := filepath.Join(workingDir, "bin", "console")
+	if exists, err := fs.Exists(console); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, nil
+	}
+
+	transports := []string{"async"}
+	if value := os.Getenv("BP_PHP_SYMFONY_MESSENGER_TRANSPORTS"); value != "" {
+		transports = strings.Fields(strings.ReplaceAll(value, ",", " "))
+	}
+
+	args := append([]string{console, "messenger:consume"}, transports...)
+	if value := os.Getenv("BP_PHP_SYMFONY_MESSENGER_TIME_LIMIT"); value != "" {
+		if _, err := strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("failed to parse BP_PHP_SYMFONY_MESSENGER_TIME_LIMIT value %s: %w", value, err)
+		}
+		args = append(args, fmt.Sprintf("--time-limit=%s", value))
+	}
+
+	if value := os.Getenv("BP_PHP_SYMFONY_MESSENGER_MEMORY_LIMIT"); value != "" {
+		args = append(args, fmt.Sprintf("--memory-limit=%s", value))
+	}
+	args = append(args, "--no-interaction")
+
+	instances := 1
+	if value := os.Getenv("BP_PHP_SYMFONY_MESSENGER_INSTANCES"); value != "" {
+		instances, err = strconv.Atoi(value)
+		if err != nil || instances < 1 {
+			return nil, fmt.Errorf("failed to parse BP_PHP_SYMFONY_MESSENGER_INSTANCES value %s: must be a positive number", value)
+		}
+	}
+
+	// The consumer finishes handling its current message before it exits
+	// when it receives SIGTERM or SIGINT.
+	stopSignal := "SIGTERM"
+	if value := os.Getenv("BP_PHP_SYMFONY_MESSENGER_STOP_SIGNAL"); value != "" {
+		if _, err := ParseSignal(value); err != nil {
+			return nil, fmt.Errorf("failed to parse BP_PHP_SYMFONY_MESSENGER_STOP_SIGNAL value %s: %w", value, err)
+		}
+		stopSignal = value
+	}
+
+	var workers []worker
+	for i := 1; i <= instances; i++ {
+		name := SymfonyMessenger
+		if instances > 1 {
+			name = fmt.Sprintf("%s-%d", SymfonyMessenger, i)
+		}
+
+		proc := NewProc("php", args)
+		proc.StopSignal = stopSignal
+
+		workers = append(workers, worker{name: name, proc: proc})
+	}
+
+	return workers, nil
+}
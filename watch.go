@@ -0,0 +1,101 @@
+package phpstart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/paketo-buildpacks/packit/v2/fs"
+)
+
+// LiveReloadEnv is set in the environment of the reload-web launch process,
+// and makes the procmgr-binary watch the paths of each process, see
+// WatchPath.
+const LiveReloadEnv = "PHP_START_LIVE_RELOAD"
+
+// WatchPath is a file or directory that the procmgr-binary watches when live
+// reload is enabled. When it changes, the process that watches it is sent the
+// signal, for example so that php-fpm gracefully reloads when an ini file
+// changes, or the command is run, for servers that reload through a command.
+//
+// Only changes to the files that match one of the Include globs, and none of
+// the Ignore globs, are taken into account. The globs are relative to the
+// path, and an empty Include matches every file.
+type WatchPath struct {
+	Path    string   `yaml:"path"`
+	Signal  string   `yaml:"signal,omitempty"`
+	Command []string `yaml:"command,omitempty"`
+	Include []string `yaml:"include,omitempty"`
+	Ignore  []string `yaml:"ignore,omitempty"`
+}
+
+// ReadWatchPaths returns the extra paths that the process with the given name
+// watches, from the comma-separated list in $BP_PHP_RELOAD_WATCH_<NAME>, such
+// as "config,.php.ini.d:SIGUSR2". Paths are relative to the app in
+// workingDir. A path with a signal is returned as a WatchPath that sends the
+// process that signal, and the others are returned as paths whose changes
+// restart all processes.
+func ReadWatchPaths(workingDir, name string) (restart []string, watch []WatchPath, err error) {
+	envVar := processEnvName("BP_PHP_RELOAD_WATCH_", name)
+
+	for _, entry := range strings.Split(os.Getenv(envVar), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		path, signal, found := strings.Cut(entry, ":")
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+
+		if !found {
+			restart = append(restart, path)
+			continue
+		}
+
+		if _, err := ParseSignal(signal); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s value %s: %w", envVar, entry, err)
+		}
+		watch = append(watch, WatchPath{Path: path, Signal: signal})
+	}
+
+	return restart, watch, nil
+}
+
+// processEnvName returns the name of the env var that configures the named
+// process, such as BP_PHP_LOG_FILES_LARAVEL_QUEUE for the prefix
+// BP_PHP_LOG_FILES_ and the process laravel-queue.
+func processEnvName(prefix, name string) string {
+	return prefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// IniDirs returns the directories that PHP reads ini files from: $PHPRC, the
+// directories in $PHP_INI_SCAN_DIR, and the .php.ini.d directory of the app
+// in workingDir. Directories that do not exist are left out.
+func IniDirs(workingDir, phprcPath string) ([]string, error) {
+	candidates := []string{phprcPath}
+	candidates = append(candidates, filepath.SplitList(os.Getenv("PHP_INI_SCAN_DIR"))...)
+	candidates = append(candidates, filepath.Join(workingDir, ".php.ini.d"))
+
+	var dirs []string
+	seen := map[string]bool{}
+	for _, dir := range candidates {
+		if dir == "" || seen[filepath.Clean(dir)] {
+			continue
+		}
+		seen[filepath.Clean(dir)] = true
+
+		exists, err := fs.Exists(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs, nil
+}
@@ -0,0 +1,31 @@
+package phpstart
+
+// worker is a supervised background process, such as a queue worker, that
+// runs next to the server. Each worker is also available as its own launch
+// process type with the same name.
+type worker struct {
+	name string
+	proc Proc
+}
+
+// workers returns the background processes that are enabled for the app in
+// the given directory. Workers are restarted by the procmgr-binary when they
+// exit, since most of them exit on purpose after a time or memory limit.
+func workers(workingDir string) ([]worker, error) {
+	laravel, err := laravelWorkers(workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	symfony, err := symfonyWorkers(workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	all := append(laravel, symfony...)
+	for i := range all {
+		all[i].proc.Restart = true
+	}
+
+	return all, nil
+}
@@ -0,0 +1,121 @@
+package phpstart
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WorkersPerCPUEnv is the launch env var through which Build passes the
+// WorkersPerCPU of the server to the server-tuner exec.d binary, such as
+// "PHP_START_NGINX_WORKER_PROCESSES=1".
+const WorkersPerCPUEnv = "PHP_START_WORKERS_PER_CPU"
+
+// WorkerTuner is implemented by servers whose number of workers is fixed in
+// their configuration, rather than derived from the CPUs of the container. At
+// launch, the server-tuner exec.d binary sets each env var that
+// WorkersPerCPU returns to that number of workers for each CPU of the
+// container, and the start command applies them with the arguments that
+// WorkerArgs returns.
+type WorkerTuner interface {
+	WorkersPerCPU() map[string]int
+
+	// WorkerArgs returns the arguments for the server configuration at
+	// configPath, or none when that configuration sets the number of workers
+	// itself.
+	WorkerArgs(configPath string) ([]string, error)
+}
+
+// httpdThreadsPerChild is the default ThreadsPerChild of the HTTPD event and
+// worker MPMs.
+const httpdThreadsPerChild = 25
+
+// WorkersPerCPU runs an HTTPD child process per CPU.
+func (HttpdServer) WorkersPerCPU() map[string]int {
+	return map[string]int{
+		"PHP_START_HTTPD_SERVER_LIMIT":        1,
+		"PHP_START_HTTPD_MAX_REQUEST_WORKERS": httpdThreadsPerChild,
+	}
+}
+
+// WorkerArgs sets ServerLimit and MaxRequestWorkers after the configuration
+// is read, so that they take precedence over the ones it sets.
+func (HttpdServer) WorkerArgs(string) ([]string, error) {
+	return []string{
+		"-c", "ServerLimit ${PHP_START_HTTPD_SERVER_LIMIT}",
+		"-c", "MaxRequestWorkers ${PHP_START_HTTPD_MAX_REQUEST_WORKERS}",
+	}, nil
+}
+
+// WorkersPerCPU runs an Nginx worker process per CPU.
+func (NginxServer) WorkersPerCPU() map[string]int {
+	return map[string]int{
+		"PHP_START_NGINX_WORKER_PROCESSES": 1,
+	}
+}
+
+var nginxWorkerProcesses = regexp.MustCompile(`(?m)^\s*worker_processes\s`)
+
+// WorkerArgs sets worker_processes as a global directive, unless the
+// configuration sets it already, since Nginx fails to start when it is set
+// twice.
+func (NginxServer) WorkerArgs(configPath string) ([]string, error) {
+	config, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Nginx configuration: %w", err)
+	}
+
+	if nginxWorkerProcesses.Match(config) {
+		return nil, nil
+	}
+
+	return []string{"-g", "worker_processes ${PHP_START_NGINX_WORKER_PROCESSES};"}, nil
+}
+
+// TuneWorkers returns the value of each env var for the given CPU quota,
+// which can be a fraction of a CPU.
+func TuneWorkers(perCPU map[string]int, cpus float64) map[string]string {
+	processes := max(int(math.Ceil(cpus)), 1)
+
+	env := map[string]string{}
+	for name, workers := range perCPU {
+		env[name] = strconv.Itoa(processes * workers)
+	}
+	return env
+}
+
+// FormatWorkersPerCPU returns the value of $PHP_START_WORKERS_PER_CPU for the
+// given workers per CPU.
+func FormatWorkersPerCPU(perCPU map[string]int) string {
+	var entries []string
+	for name, workers := range perCPU {
+		entries = append(entries, fmt.Sprintf("%s=%d", name, workers))
+	}
+	sort.Strings(entries)
+
+	return strings.Join(entries, ",")
+}
+
+// ParseWorkersPerCPU parses a value of $PHP_START_WORKERS_PER_CPU.
+func ParseWorkersPerCPU(value string) (map[string]int, error) {
+	perCPU := map[string]int{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, workers, found := strings.Cut(entry, "=")
+		count, err := strconv.Atoi(workers)
+		if !found || name == "" || err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid workers per CPU %q: must be NAME=<number of workers>", entry)
+		}
+		perCPU[name] = count
+	}
+
+	return perCPU, nil
+}
@@ -0,0 +1,80 @@
+package phpstart_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	phpstart "github.com/paketo-buildpacks/php-start"
+	"github.com/sclevine/spec"
+
+	. "github.com/onsi/gomega"
+)
+
+func testWorkerTuning(t *testing.T, context spec.G, it spec.S) {
+	var Expect = NewWithT(t).Expect
+
+	context("HTTPD", func() {
+		it("runs a child process per CPU", func() {
+			Expect(phpstart.TuneWorkers(phpstart.NewHttpdServer().WorkersPerCPU(), 1.5)).To(Equal(map[string]string{
+				"PHP_START_HTTPD_SERVER_LIMIT":        "2",
+				"PHP_START_HTTPD_MAX_REQUEST_WORKERS": "50",
+			}))
+		})
+	})
+
+	context("Nginx", func() {
+		var configPath string
+
+		it.Before(func() {
+			configPath = filepath.Join(t.TempDir(), "nginx.conf")
+			Expect(os.WriteFile(configPath, []byte("events {}\n"), 0644)).To(Succeed())
+		})
+
+		it("runs a worker process per CPU", func() {
+			Expect(phpstart.TuneWorkers(phpstart.NewNginxServer().WorkersPerCPU(), 4)).To(Equal(map[string]string{
+				"PHP_START_NGINX_WORKER_PROCESSES": "4",
+			}))
+		})
+
+		it("runs at least one worker process", func() {
+			Expect(phpstart.TuneWorkers(phpstart.NewNginxServer().WorkersPerCPU(), 0)).To(Equal(map[string]string{
+				"PHP_START_NGINX_WORKER_PROCESSES": "1",
+			}))
+		})
+
+		it("sets worker_processes as a global directive", func() {
+			Expect(phpstart.NewNginxServer().WorkerArgs(configPath)).To(Equal([]string{"-g", "worker_processes ${PHP_START_NGINX_WORKER_PROCESSES};"}))
+		})
+
+		context("when the configuration sets worker_processes", func() {
+			it.Before(func() {
+				Expect(os.WriteFile(configPath, []byte("worker_processes auto;\nevents {}\n"), 0644)).To(Succeed())
+			})
+
+			it("does not set it again", func() {
+				Expect(phpstart.NewNginxServer().WorkerArgs(configPath)).To(BeEmpty())
+			})
+		})
+
+		context("when the configuration cannot be read", func() {
+			it("returns an error", func() {
+				_, err := phpstart.NewNginxServer().WorkerArgs(filepath.Join(t.TempDir(), "missing.conf"))
+				Expect(err).To(MatchError(ContainSubstring("failed to read Nginx configuration")))
+			})
+		})
+	})
+
+	context("WorkersPerCPU", func() {
+		it("round trips through $PHP_START_WORKERS_PER_CPU", func() {
+			value := phpstart.FormatWorkersPerCPU(phpstart.NewHttpdServer().WorkersPerCPU())
+			Expect(value).To(Equal("PHP_START_HTTPD_MAX_REQUEST_WORKERS=25,PHP_START_HTTPD_SERVER_LIMIT=1"))
+			Expect(phpstart.ParseWorkersPerCPU(value)).To(Equal(phpstart.NewHttpdServer().WorkersPerCPU()))
+		})
+
+		it("rejects invalid values", func() {
+			_, err := phpstart.ParseWorkersPerCPU("PHP_START_NGINX_WORKER_PROCESSES=many")
+			Expect(err).To(MatchError(`invalid workers per CPU "PHP_START_NGINX_WORKER_PROCESSES=many": must be NAME=<number of workers>`))
+		})
+	})
+}